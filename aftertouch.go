@@ -0,0 +1,154 @@
+package midi
+
+import "errors"
+
+// NewPolyAfterTouch returns a new polyphonic aftertouch event
+// time     - The number of ticks since the previous event, default is 0
+// channel  - The channel of the event
+// p        - The pitch of the note {Note|Pitch}
+// pressure - The pressure on the note, between 0 and 127
+func NewPolyAfterTouch(time []byte, channel int, p Pitchier, pressure byte) (*NormalEvent, error) {
+	return NewPolyAfterTouchAfter(ParseTickTime(time), channel, p, pressure)
+}
+
+// NewPolyAfterTouchAfter returns a new polyphonic aftertouch event
+// delta    - The number of ticks since the previous event
+// channel  - The channel of the event
+// p        - The pitch of the note {Note|Pitch}
+// pressure - The pressure on the note, between 0 and 127
+func NewPolyAfterTouchAfter(delta, channel int, p Pitchier, pressure byte) (*NormalEvent, error) {
+	if pressure > 0x7F {
+		return nil, errors.New("pressure out of bounds")
+	}
+	p1, err := EnsurePitch(p)
+	if err != nil {
+		return nil, err
+	}
+	return NewEventAfter(delta, EventAfterTouch, channel, byte(p1), pressure)
+}
+
+// NewChannelPressure returns a new channel aftertouch event
+// time     - The number of ticks since the previous event, default is 0
+// channel  - The channel of the event
+// pressure - The pressure on the channel, between 0 and 127
+func NewChannelPressure(time []byte, channel int, pressure byte) (*NormalEvent, error) {
+	return NewChannelPressureAfter(ParseTickTime(time), channel, pressure)
+}
+
+// NewChannelPressureAfter returns a new channel aftertouch event
+// delta    - The number of ticks since the previous event
+// channel  - The channel of the event
+// pressure - The pressure on the channel, between 0 and 127
+func NewChannelPressureAfter(delta, channel int, pressure byte) (*NormalEvent, error) {
+	if pressure > 0x7F {
+		return nil, errors.New("pressure out of bounds")
+	}
+	return NewEventAfter(delta, EventChannelAfterTouch, channel, pressure, 0)
+}
+
+// AddPolyAfterTouch adds a polyphonic aftertouch event to the track
+// channel  - The channel to add the event to
+// p        - The pitch of the note {Note|Pitch}
+// pressure - The pressure on the note, between 0 and 127
+// time     - The number of ticks since the previous event, default is 0
+func (t *Track) AddPolyAfterTouch(channel int, p Pitchier, pressure byte, time []byte) *Track {
+	return t.PolyAfterTouchAfter(channel, p, pressure, ParseTickTime(time))
+}
+
+// PolyAfterTouch adds a polyphonic aftertouch event to the track
+// channel  - The channel to add the event to
+// p        - The pitch of the note {Note|Pitch}
+// pressure - The pressure on the note, between 0 and 127
+// time     - The number of ticks since the previous event, default is 0
+func (t *Track) PolyAfterTouch(channel int, p Pitchier, pressure byte, time []byte) *Track {
+	return t.AddPolyAfterTouch(channel, p, pressure, time)
+}
+
+// PolyAfterTouchAfter adds a polyphonic aftertouch event to the track
+// channel  - The channel to add the event to
+// p        - The pitch of the note {Note|Pitch}
+// pressure - The pressure on the note, between 0 and 127
+// delta    - The number of ticks since the previous event
+func (t *Track) PolyAfterTouchAfter(channel int, p Pitchier, pressure byte, delta int) *Track {
+	return t.add(NewPolyAfterTouchAfter(delta, channel, p, pressure))
+}
+
+// AddChannelPressure adds a channel aftertouch event to the track
+// channel  - The channel to add the event to
+// pressure - The pressure on the channel, between 0 and 127
+// time     - The number of ticks since the previous event, default is 0
+func (t *Track) AddChannelPressure(channel int, pressure byte, time []byte) *Track {
+	return t.ChannelPressureAfter(channel, pressure, ParseTickTime(time))
+}
+
+// ChannelPressure adds a channel aftertouch event to the track
+// channel  - The channel to add the event to
+// pressure - The pressure on the channel, between 0 and 127
+// time     - The number of ticks since the previous event, default is 0
+func (t *Track) ChannelPressure(channel int, pressure byte, time []byte) *Track {
+	return t.AddChannelPressure(channel, pressure, time)
+}
+
+// ChannelPressureAfter adds a channel aftertouch event to the track
+// channel  - The channel to add the event to
+// pressure - The pressure on the channel, between 0 and 127
+// delta    - The number of ticks since the previous event
+func (t *Track) ChannelPressureAfter(channel int, pressure byte, delta int) *Track {
+	return t.add(NewChannelPressureAfter(delta, channel, pressure))
+}
+
+// PolyAfterTouchRamp adds polyphonic aftertouch events to the track going
+// from one pressure to another, the last event always has the final pressure
+// channel - The channel to add the events to
+// p       - The pitch of the note {Note|Pitch}
+// from    - The pressure at the start of the ramp
+// to      - The pressure at the end of the ramp
+// dur     - The duration of the ramp, in ticks
+// step    - The ticks between each event of the ramp
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) PolyAfterTouchRamp(channel int, p Pitchier, from, to byte, dur, step int, time []byte) *Track {
+	return t.PolyAfterTouchRampAfter(channel, p, from, to, dur, step, ParseTickTime(time))
+}
+
+// PolyAfterTouchRampAfter adds polyphonic aftertouch events to the track
+// going from one pressure to another, the last event always has the final
+// pressure
+// channel - The channel to add the events to
+// p       - The pitch of the note {Note|Pitch}
+// from    - The pressure at the start of the ramp
+// to      - The pressure at the end of the ramp
+// dur     - The duration of the ramp, in ticks
+// step    - The ticks between each event of the ramp
+// delta   - The number of ticks since the previous event
+func (t *Track) PolyAfterTouchRampAfter(channel int, p Pitchier, from, to byte, dur, step, delta int) *Track {
+	return t.ramp(float64(from), float64(to), dur, step, delta, func(v float64, delta int) *Track {
+		return t.PolyAfterTouchAfter(channel, p, byte(v), delta)
+	})
+}
+
+// ChannelPressureRamp adds channel aftertouch events to the track going
+// from one pressure to another, the last event always has the final pressure
+// channel - The channel to add the events to
+// from    - The pressure at the start of the ramp
+// to      - The pressure at the end of the ramp
+// dur     - The duration of the ramp, in ticks
+// step    - The ticks between each event of the ramp
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) ChannelPressureRamp(channel int, from, to byte, dur, step int, time []byte) *Track {
+	return t.ChannelPressureRampAfter(channel, from, to, dur, step, ParseTickTime(time))
+}
+
+// ChannelPressureRampAfter adds channel aftertouch events to the track
+// going from one pressure to another, the last event always has the final
+// pressure
+// channel - The channel to add the events to
+// from    - The pressure at the start of the ramp
+// to      - The pressure at the end of the ramp
+// dur     - The duration of the ramp, in ticks
+// step    - The ticks between each event of the ramp
+// delta   - The number of ticks since the previous event
+func (t *Track) ChannelPressureRampAfter(channel int, from, to byte, dur, step, delta int) *Track {
+	return t.ramp(float64(from), float64(to), dur, step, delta, func(v float64, delta int) *Track {
+		return t.ChannelPressureAfter(channel, byte(v), delta)
+	})
+}
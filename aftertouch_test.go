@@ -0,0 +1,145 @@
+package midi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPolyAfterTouch(t *testing.T) {
+	type args struct {
+		p        Pitchier
+		pressure byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"note pressure",
+			args{Note("c4"), 100},
+			Codes{0x0, 0xa3, 0x3c, 0x64},
+			false,
+		},
+		{
+			"zero pressure",
+			args{Pitch(61), 0},
+			Codes{0x0, 0xa3, 0x3d, 0x0},
+			false,
+		},
+		{
+			"invalid pitch",
+			args{Note("c"), 100},
+			nil,
+			true,
+		},
+		{
+			"invalid pressure",
+			args{Pitch(60), 128},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPolyAfterTouch(nil, 3, tt.args.p, tt.args.pressure)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPolyAfterTouch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewPolyAfterTouch() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewChannelPressure(t *testing.T) {
+	tests := []struct {
+		name     string
+		pressure byte
+		want     Codes
+		wantErr  bool
+	}{
+		{
+			"pressure",
+			100,
+			Codes{0x0, 0xd3, 0x64},
+			false,
+		},
+		{
+			"zero pressure",
+			0,
+			Codes{0x0, 0xd3, 0x0},
+			false,
+		},
+		{
+			"invalid pressure",
+			128,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewChannelPressure(nil, 3, tt.pressure)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewChannelPressure() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewChannelPressure() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTrack_AfterTouchRamps(t *testing.T) {
+	tests := []struct {
+		name string
+		got  *Track
+		want *Track
+	}{
+		{
+			"poly aftertouch ramp",
+			NewTrack().PolyAfterTouchRamp(0, Note("c4"), 0, 127, 10, 5, nil),
+			NewTrack().
+				PolyAfterTouch(0, Note("c4"), 0, nil).
+				PolyAfterTouch(0, Note("c4"), 64, TranslateTickTime(5)).
+				PolyAfterTouch(0, Note("c4"), 127, TranslateTickTime(5)),
+		},
+		{
+			"channel pressure ramp",
+			NewTrack().ChannelPressureRamp(0, 100, 0, 8, 3, nil),
+			NewTrack().
+				ChannelPressure(0, 100, nil).
+				ChannelPressure(0, 63, TranslateTickTime(3)).
+				ChannelPressure(0, 25, TranslateTickTime(3)).
+				ChannelPressure(0, 0, TranslateTickTime(2)),
+		},
+		{
+			"invalid pitch",
+			NewTrack().PolyAfterTouchRamp(0, Note("c"), 0, 127, 10, 5, nil),
+			nil,
+		},
+		{
+			"invalid pressure",
+			NewTrack().ChannelPressureRamp(0, 0, 128, 10, 5, nil),
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.want == nil {
+				if tt.got.Err() == nil {
+					t.Errorf("Track ramp error = nil, want error")
+				}
+				return
+			}
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("Track ramp = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
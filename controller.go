@@ -0,0 +1,232 @@
+package midi
+
+import "errors"
+
+// Controller is the number of a midi controller
+type Controller byte
+
+const (
+	// ControllerBankSelectMSB selects the bank, coarse
+	ControllerBankSelectMSB Controller = 0
+	// ControllerModulation is the modulation wheel, coarse
+	ControllerModulation Controller = 1
+	// ControllerBreath is the breath controller, coarse
+	ControllerBreath Controller = 2
+	// ControllerFoot is the foot pedal, coarse
+	ControllerFoot Controller = 4
+	// ControllerPortamentoTime is the portamento time, coarse
+	ControllerPortamentoTime Controller = 5
+	// ControllerDataEntryMSB is the data entry for RPN and NRPN, coarse
+	ControllerDataEntryMSB Controller = 6
+	// ControllerVolume is the channel volume, coarse
+	ControllerVolume Controller = 7
+	// ControllerBalance is the balance, coarse
+	ControllerBalance Controller = 8
+	// ControllerPan is the pan, coarse
+	ControllerPan Controller = 10
+	// ControllerExpression is the expression, coarse
+	ControllerExpression Controller = 11
+	// ControllerBankSelectLSB selects the bank, fine
+	ControllerBankSelectLSB Controller = 32
+	// ControllerModulationLSB is the modulation wheel, fine
+	ControllerModulationLSB Controller = 33
+	// ControllerDataEntryLSB is the data entry for RPN and NRPN, fine
+	ControllerDataEntryLSB Controller = 38
+	// ControllerVolumeLSB is the channel volume, fine
+	ControllerVolumeLSB Controller = 39
+	// ControllerPanLSB is the pan, fine
+	ControllerPanLSB Controller = 42
+	// ControllerExpressionLSB is the expression, fine
+	ControllerExpressionLSB Controller = 43
+	// ControllerSustain is the sustain (damper) pedal
+	ControllerSustain Controller = 64
+	// ControllerPortamento is the portamento switch
+	ControllerPortamento Controller = 65
+	// ControllerSostenuto is the sostenuto pedal
+	ControllerSostenuto Controller = 66
+	// ControllerSoftPedal is the soft pedal
+	ControllerSoftPedal Controller = 67
+	// ControllerLegato is the legato footswitch
+	ControllerLegato Controller = 68
+	// ControllerReverb is the reverb send level
+	ControllerReverb Controller = 91
+	// ControllerChorus is the chorus send level
+	ControllerChorus Controller = 93
+	// ControllerDataIncrement increments the RPN or NRPN value
+	ControllerDataIncrement Controller = 96
+	// ControllerDataDecrement decrements the RPN or NRPN value
+	ControllerDataDecrement Controller = 97
+	// ControllerNRPNLSB selects the NRPN, fine
+	ControllerNRPNLSB Controller = 98
+	// ControllerNRPNMSB selects the NRPN, coarse
+	ControllerNRPNMSB Controller = 99
+	// ControllerRPNLSB selects the RPN, fine
+	ControllerRPNLSB Controller = 100
+	// ControllerRPNMSB selects the RPN, coarse
+	ControllerRPNMSB Controller = 101
+	// ControllerAllSoundOff silences the channel immediately
+	ControllerAllSoundOff Controller = 120
+	// ControllerResetAllControllers resets every controller of the channel
+	ControllerResetAllControllers Controller = 121
+	// ControllerLocalControl turns local control on or off
+	ControllerLocalControl Controller = 122
+	// ControllerAllNotesOff releases every note of the channel
+	ControllerAllNotesOff Controller = 123
+	// ControllerOmniOff turns omni mode off
+	ControllerOmniOff Controller = 124
+	// ControllerOmniOn turns omni mode on
+	ControllerOmniOn Controller = 125
+	// ControllerMonoOn turns mono mode on
+	ControllerMonoOn Controller = 126
+	// ControllerPolyOn turns poly mode on
+	ControllerPolyOn Controller = 127
+)
+
+// NewControlChange returns a new controller event
+// time       - The number of ticks since the previous event, default is 0
+// channel    - The channel of the event
+// controller - The controller to change
+// value      - The new value of the controller, between 0 and 127
+func NewControlChange(time []byte, channel int, controller Controller, value byte) (*NormalEvent, error) {
+	return NewControlChangeAfter(ParseTickTime(time), channel, controller, value)
+}
+
+// NewControlChangeAfter returns a new controller event
+// delta      - The number of ticks since the previous event
+// channel    - The channel of the event
+// controller - The controller to change
+// value      - The new value of the controller, between 0 and 127
+func NewControlChangeAfter(delta, channel int, controller Controller, value byte) (*NormalEvent, error) {
+	if controller > 0x7F {
+		return nil, errors.New("invalid controller")
+	}
+	if value > 0x7F {
+		return nil, errors.New("controller value out of bounds")
+	}
+	return NewEventAfter(delta, EventController, channel, byte(controller), value)
+}
+
+// AddControlChange adds a controller event to the track
+// channel    - The channel to add the event to
+// controller - The controller to change
+// value      - The new value of the controller, between 0 and 127
+// time       - The number of ticks since the previous event, default is 0
+func (t *Track) AddControlChange(channel int, controller Controller, value byte, time []byte) *Track {
+	return t.ControlChangeAfter(channel, controller, value, ParseTickTime(time))
+}
+
+// ControlChange adds a controller event to the track
+// channel    - The channel to add the event to
+// controller - The controller to change
+// value      - The new value of the controller, between 0 and 127
+// time       - The number of ticks since the previous event, default is 0
+func (t *Track) ControlChange(channel int, controller Controller, value byte, time []byte) *Track {
+	return t.AddControlChange(channel, controller, value, time)
+}
+
+// ControlChangeAfter adds a controller event to the track
+// channel    - The channel to add the event to
+// controller - The controller to change
+// value      - The new value of the controller, between 0 and 127
+// delta      - The number of ticks since the previous event
+func (t *Track) ControlChangeAfter(channel int, controller Controller, value byte, delta int) *Track {
+	return t.add(NewControlChangeAfter(delta, channel, controller, value))
+}
+
+// Volume sets the volume of a channel, between 0 and 127
+func (t *Track) Volume(channel int, value byte, time []byte) *Track {
+	return t.VolumeAfter(channel, value, ParseTickTime(time))
+}
+
+// VolumeAfter sets the volume of a channel, between 0 and 127
+func (t *Track) VolumeAfter(channel int, value byte, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerVolume, value, delta)
+}
+
+// Pan sets the pan of a channel, 0 is left, 64 center and 127 right
+func (t *Track) Pan(channel int, value byte, time []byte) *Track {
+	return t.PanAfter(channel, value, ParseTickTime(time))
+}
+
+// PanAfter sets the pan of a channel, 0 is left, 64 center and 127 right
+func (t *Track) PanAfter(channel int, value byte, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerPan, value, delta)
+}
+
+// Expression sets the expression of a channel, between 0 and 127
+func (t *Track) Expression(channel int, value byte, time []byte) *Track {
+	return t.ExpressionAfter(channel, value, ParseTickTime(time))
+}
+
+// ExpressionAfter sets the expression of a channel, between 0 and 127
+func (t *Track) ExpressionAfter(channel int, value byte, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerExpression, value, delta)
+}
+
+// Modulation sets the modulation wheel of a channel, between 0 and 127
+func (t *Track) Modulation(channel int, value byte, time []byte) *Track {
+	return t.ModulationAfter(channel, value, ParseTickTime(time))
+}
+
+// ModulationAfter sets the modulation wheel of a channel, between 0 and 127
+func (t *Track) ModulationAfter(channel int, value byte, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerModulation, value, delta)
+}
+
+// Sustain presses or releases the sustain pedal of a channel
+func (t *Track) Sustain(channel int, on bool, time []byte) *Track {
+	return t.SustainAfter(channel, on, ParseTickTime(time))
+}
+
+// SustainAfter presses or releases the sustain pedal of a channel
+func (t *Track) SustainAfter(channel int, on bool, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerSustain, pedal(on), delta)
+}
+
+// Sostenuto presses or releases the sostenuto pedal of a channel
+func (t *Track) Sostenuto(channel int, on bool, time []byte) *Track {
+	return t.SostenutoAfter(channel, on, ParseTickTime(time))
+}
+
+// SostenutoAfter presses or releases the sostenuto pedal of a channel
+func (t *Track) SostenutoAfter(channel int, on bool, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerSostenuto, pedal(on), delta)
+}
+
+// SoftPedal presses or releases the soft pedal of a channel
+func (t *Track) SoftPedal(channel int, on bool, time []byte) *Track {
+	return t.SoftPedalAfter(channel, on, ParseTickTime(time))
+}
+
+// SoftPedalAfter presses or releases the soft pedal of a channel
+func (t *Track) SoftPedalAfter(channel int, on bool, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerSoftPedal, pedal(on), delta)
+}
+
+// AllNotesOff releases every note playing on a channel
+func (t *Track) AllNotesOff(channel int, time []byte) *Track {
+	return t.AllNotesOffAfter(channel, ParseTickTime(time))
+}
+
+// AllNotesOffAfter releases every note playing on a channel
+func (t *Track) AllNotesOffAfter(channel, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerAllNotesOff, 0, delta)
+}
+
+// ResetAllControllers resets every controller of a channel
+func (t *Track) ResetAllControllers(channel int, time []byte) *Track {
+	return t.ResetAllControllersAfter(channel, ParseTickTime(time))
+}
+
+// ResetAllControllersAfter resets every controller of a channel
+func (t *Track) ResetAllControllersAfter(channel, delta int) *Track {
+	return t.ControlChangeAfter(channel, ControllerResetAllControllers, 0, delta)
+}
+
+// pedal returns the controller value of a pedal switch
+func pedal(on bool) byte {
+	if on {
+		return 127
+	}
+	return 0
+}
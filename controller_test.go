@@ -0,0 +1,134 @@
+package midi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewControlChange(t *testing.T) {
+	type args struct {
+		channel    int
+		controller Controller
+		value      byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"volume",
+			args{3, ControllerVolume, 100},
+			Codes{0x0, 0xb3, 0x7, 0x64},
+			false,
+		},
+		{
+			"zero value",
+			args{0, ControllerBankSelectMSB, 0},
+			Codes{0x0, 0xb0, 0x0, 0x0},
+			false,
+		},
+		{
+			"invalid controller",
+			args{0, 128, 0},
+			nil,
+			true,
+		},
+		{
+			"invalid value",
+			args{0, ControllerPan, 128},
+			nil,
+			true,
+		},
+		{
+			"invalid channel",
+			args{16, ControllerPan, 64},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewControlChange(nil, tt.args.channel, tt.args.controller, tt.args.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewControlChange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewControlChange() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTrack_ControlChange(t *testing.T) {
+	tests := []struct {
+		name string
+		t    *Track
+		want Codes
+	}{
+		{
+			"control change",
+			NewTrack().ControlChange(1, ControllerReverb, 40, nil),
+			Codes{0x0, 0xb1, 0x5b, 0x28},
+		},
+		{
+			"volume",
+			NewTrack().Volume(1, 100, nil),
+			Codes{0x0, 0xb1, 0x7, 0x64},
+		},
+		{
+			"pan",
+			NewTrack().Pan(1, 64, nil),
+			Codes{0x0, 0xb1, 0xa, 0x40},
+		},
+		{
+			"expression",
+			NewTrack().Expression(1, 90, nil),
+			Codes{0x0, 0xb1, 0xb, 0x5a},
+		},
+		{
+			"modulation",
+			NewTrack().Modulation(1, 10, nil),
+			Codes{0x0, 0xb1, 0x1, 0xa},
+		},
+		{
+			"sustain",
+			NewTrack().Sustain(1, true, nil).Sustain(1, false, TranslateTickTime(96)),
+			Codes{0x0, 0xb1, 0x40, 0x7f, 0x60, 0xb1, 0x40, 0x0},
+		},
+		{
+			"sostenuto",
+			NewTrack().Sostenuto(1, true, nil),
+			Codes{0x0, 0xb1, 0x42, 0x7f},
+		},
+		{
+			"soft pedal",
+			NewTrack().SoftPedal(1, true, nil),
+			Codes{0x0, 0xb1, 0x43, 0x7f},
+		},
+		{
+			"all notes off",
+			NewTrack().AllNotesOff(1, nil),
+			Codes{0x0, 0xb1, 0x7b, 0x0},
+		},
+		{
+			"reset all controllers",
+			NewTrack().ResetAllControllers(1, nil),
+			Codes{0x0, 0xb1, 0x79, 0x0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.t.Bytes()
+			// skip the chunk header and the end of track
+			if got := got[8 : len(got)-4]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Track.ControlChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if err := NewTrack().Pan(1, 128, nil).Err(); err == nil {
+		t.Errorf("Track.Pan() error = nil, want error")
+	}
+}
@@ -0,0 +1,136 @@
+package midi
+
+import (
+	"errors"
+	"sort"
+)
+
+// timedEvent is an event along with its absolute time in ticks
+type timedEvent struct {
+	tick int
+	e    Event
+}
+
+// byTick sorts timed events by their absolute time
+type byTick []timedEvent
+
+func (s byTick) Len() int           { return len(s) }
+func (s byTick) Less(i, j int) bool { return s[i].tick < s[j].tick }
+func (s byTick) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// ToFormat0 returns a new Format0 file with the events of every track
+// of f merged by absolute time into a single track. Events at the same
+// time keep the order of their tracks, the track ends with the last
+// track of f to end
+func (f *File) ToFormat0() (*File, error) {
+	if f.Format() == Format2 {
+		return nil, errors.New("format 2 files can't be merged")
+	}
+	events := []timedEvent{}
+	end := 0
+	for _, t := range f.tracks {
+		events = append(events, t.timeline()...)
+		if t.end > end {
+			end = t.end
+		}
+	}
+	sort.Stable(byTick(events))
+	track := newTrackFromTimeline(events)
+	track.end = end
+	nf := &File{
+		division: f.division,
+		tracks:   []*Track{track},
+	}
+	return nf, nf.SetFormat(Format0)
+}
+
+// ToFormat1 returns a new Format1 file splitting the single track of f
+// into a conductor track, holding every event not bound to a channel
+// (tempo, time signature and other meta events), plus one track per
+// channel in ascending channel order. Every track ends where the
+// track of f does
+func (f *File) ToFormat1() (*File, error) {
+	if len(f.tracks) != 1 {
+		return nil, errors.New("only single track files can be split")
+	}
+	conductor := []timedEvent{}
+	channels := map[int][]timedEvent{}
+	for _, te := range f.tracks[0].timeline() {
+		if e, ok := te.e.(*NormalEvent); ok {
+			channels[e.channel] = append(channels[e.channel], te)
+		} else {
+			conductor = append(conductor, te)
+		}
+	}
+	end := f.tracks[0].end
+	nf := &File{
+		division: f.division,
+		tracks:   []*Track{newTrackFromTimeline(conductor)},
+	}
+	nf.tracks[0].end = end
+	for channel := 0; channel < 16; channel++ {
+		if events, ok := channels[channel]; ok {
+			track := newTrackFromTimeline(events)
+			track.end = end
+			nf.AddTrack(track)
+		}
+	}
+	return nf, nf.SetFormat(Format1)
+}
+
+// timeline returns copies of the events of the track, including the
+// ones placed at absolute times and the scheduled note-offs, along with
+// their absolute time
+func (t *Track) timeline() []timedEvent {
+	events := make([]timedEvent, 0, len(t.offs)+len(t.events)+len(t.placed))
+	// the note-offs go first so they sort before the events at their time
+	for _, te := range t.offs {
+		events = append(events, timedEvent{te.tick, cloneEvent(te.e)})
+	}
+	tick := 0
+	for _, e := range t.events {
+		tick += e.Delta()
+		c := cloneEvent(e)
+		setTick(c, tick)
+		events = append(events, timedEvent{tick, c})
+	}
+	if len(t.placed) == 0 && len(t.offs) == 0 {
+		return events
+	}
+	for _, te := range t.placed {
+		events = append(events, timedEvent{te.tick, cloneEvent(te.e)})
+	}
+	sort.Stable(byTick(events))
+	return events
+}
+
+// newTrackFromTimeline returns a new track with the given events,
+// which must be sorted by absolute time, setting their delta times
+func newTrackFromTimeline(events []timedEvent) *Track {
+	t := NewTrack()
+	tick := 0
+	for _, te := range events {
+		te.e.SetTime(te.tick - tick)
+		setTick(te.e, te.tick)
+		tick = te.tick
+		t.events = append(t.events, te.e)
+	}
+	return t
+}
+
+// cloneEvent returns a copy of e, events of unknown types
+// can't be copied and are returned as they are
+func cloneEvent(e Event) Event {
+	switch v := e.(type) {
+	case *NormalEvent:
+		c := *v
+		return &c
+	case *MetaEvent:
+		c := *v
+		return &c
+	case *SysExEvent:
+		c := *v
+		return &c
+	}
+	return e
+}
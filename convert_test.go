@@ -0,0 +1,185 @@
+package midi
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestFile_ToFormat0(t *testing.T) {
+	f, _ := NewFile(DefaultTicks,
+		NewTrack().
+			Tempo(120, nil).
+			Tempo(60, TranslateTickTime(96)),
+		NewTrack().
+			NoteOn(0, Pitch(60), nil, 0).
+			NoteOff(0, Pitch(60), TranslateTickTime(96), 0),
+		NewTrack().
+			NoteOn(1, Pitch(48), TranslateTickTime(48), 0).
+			NoteOff(1, Pitch(48), TranslateTickTime(96), 0),
+	)
+	format2, _ := NewFile(DefaultTicks, NewTrack(), NewTrack())
+	format2.SetFormat(Format2)
+	tests := []struct {
+		name    string
+		f       *File
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"merge tracks",
+			f,
+			NewTrack().
+				Tempo(120, nil).
+				NoteOn(0, Pitch(60), nil, 0).
+				NoteOn(1, Pitch(48), TranslateTickTime(48), 0).
+				Tempo(60, TranslateTickTime(48)).
+				NoteOff(0, Pitch(60), nil, 0).
+				NoteOff(1, Pitch(48), TranslateTickTime(48), 0).
+				Bytes(),
+			false,
+		},
+		{
+			"format 2",
+			format2,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.f.ToFormat0()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File.ToFormat0() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Format() != Format0 || len(got.tracks) != 1 {
+				t.Fatalf("File.ToFormat0() = format %v with %d tracks", got.Format(), len(got.tracks))
+			}
+			if b := got.tracks[0].Bytes(); !reflect.DeepEqual(b, tt.want) {
+				t.Errorf("File.ToFormat0() = %v, want %v", b, tt.want)
+			}
+		})
+	}
+	// the original file must be left untouched
+	if b := f.tracks[2].Bytes(); !reflect.DeepEqual(b, NewTrack().
+		NoteOn(1, Pitch(48), TranslateTickTime(48), 0).
+		NoteOff(1, Pitch(48), TranslateTickTime(96), 0).
+		Bytes()) {
+		t.Errorf("File.ToFormat0() modified the original track: %v", b)
+	}
+}
+
+func TestFile_ToFormat1(t *testing.T) {
+	f, _ := NewFile(DefaultTicks,
+		NewTrack().
+			Tempo(120, nil).
+			NoteOn(9, Pitch(36), nil, 0).
+			NoteOn(1, Pitch(48), TranslateTickTime(48), 0).
+			Tempo(60, TranslateTickTime(48)).
+			NoteOff(9, Pitch(36), nil, 0).
+			NoteOff(1, Pitch(48), TranslateTickTime(48), 0),
+	)
+	multi, _ := NewFile(DefaultTicks, NewTrack(), NewTrack())
+	tests := []struct {
+		name    string
+		f       *File
+		want    []Codes
+		wantErr bool
+	}{
+		{
+			"split track",
+			f,
+			[]Codes{
+				NewTrack().
+					Tempo(120, nil).
+					Tempo(60, TranslateTickTime(96)).
+					Bytes(),
+				NewTrack().
+					NoteOn(1, Pitch(48), TranslateTickTime(48), 0).
+					NoteOff(1, Pitch(48), TranslateTickTime(96), 0).
+					Bytes(),
+				NewTrack().
+					NoteOn(9, Pitch(36), nil, 0).
+					NoteOff(9, Pitch(36), TranslateTickTime(96), 0).
+					Bytes(),
+			},
+			false,
+		},
+		{
+			"multiple tracks",
+			multi,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.f.ToFormat1()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File.ToFormat1() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Format() != Format1 {
+				t.Errorf("File.ToFormat1() format = %v", got.Format())
+			}
+			tracks := []Codes{}
+			for _, track := range got.tracks {
+				tracks = append(tracks, track.Bytes())
+			}
+			if !reflect.DeepEqual(tracks, tt.want) {
+				t.Errorf("File.ToFormat1() = %v, want %v", tracks, tt.want)
+			}
+		})
+	}
+}
+
+func TestFile_ConvertEndOfTrack(t *testing.T) {
+	tempo := []byte{0x0, 0xff, 0x51, 0x3, 0x7, 0xa1, 0x20}
+	// the note track ends 500 ticks after its note
+	notes := []byte{0xa, 0x90, 0x3c, 0x40, 0x83, 0x74, 0xff, 0x2f, 0x0}
+	data := bytes.Join([][]byte{
+		{0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x2, 0x0, 0x60},
+		{0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0xb}, tempo, {0x0, 0xff, 0x2f, 0x0},
+		{0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x9}, notes,
+	}, nil)
+	f, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	f0, err := f.ToFormat0()
+	if err != nil {
+		t.Fatalf("File.ToFormat0() error = %v", err)
+	}
+	want := bytes.Join([][]byte{{0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x10}, tempo, notes}, nil)
+	if got := f0.tracks[0].Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("File.ToFormat0() = %v, want %v", got, Codes(want))
+	}
+	f1, err := f0.ToFormat1()
+	if err != nil {
+		t.Fatalf("File.ToFormat1() error = %v", err)
+	}
+	wants := [][]byte{
+		bytes.Join([][]byte{{0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0xc}, tempo, {0x83, 0x7e, 0xff, 0x2f, 0x0}}, nil),
+		bytes.Join([][]byte{{0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x9}, notes}, nil),
+	}
+	for i, want := range wants {
+		if got := f1.tracks[i].Bytes(); !bytes.Equal(got, want) {
+			t.Errorf("File.ToFormat1() track %d = %v, want %v", i, got, Codes(want))
+		}
+	}
+	// and back to format 0
+	f0, err = f1.ToFormat0()
+	if err != nil {
+		t.Fatalf("File.ToFormat0() error = %v", err)
+	}
+	if got := f0.tracks[0].Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("File.ToFormat0() = %v, want %v", got, Codes(want))
+	}
+}
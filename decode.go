@@ -0,0 +1,379 @@
+package midi
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// TrackChunkID is the track chunk magic cookie
+	TrackChunkID = "MTrk"
+	// maxVLQBytes is the maximum length of a variable-length quantity
+	maxVLQBytes = 4
+)
+
+// DecodeError describes where a malformed midi file failed to decode
+type DecodeError struct {
+	// Filename is the decoded file, empty when decoding from an io.Reader
+	Filename string
+	// Offset is the byte position at which the error was detected
+	Offset int64
+	// ChunkOffset is the byte position of the chunk being decoded
+	ChunkOffset int64
+	// Track is the index of the track being decoded, -1 for the header
+	Track int
+	// Event is the index of the event within the track, -1 when
+	// the error is not related to an event
+	Event int
+	// Err is the underlying error
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	s := "midi: "
+	if e.Filename != "" {
+		s += e.Filename + ": "
+	}
+	if e.Track < 0 {
+		s += "header"
+	} else {
+		s += fmt.Sprintf("track %d", e.Track)
+	}
+	if e.Event >= 0 {
+		s += fmt.Sprintf(" event %d", e.Event)
+	}
+	return s + fmt.Sprintf(" (chunk at %d, byte %d): %v", e.ChunkOffset, e.Offset, e.Err)
+}
+
+// Decode reads a Standard MIDI File from r and returns it
+// with all of its tracks and events, errors about malformed
+// input are returned as *DecodeError
+func Decode(r io.Reader) (*File, error) {
+	rd := NewReader(r)
+	format, ntrks, division, err := rd.Header()
+	if err != nil {
+		return nil, err
+	}
+	f := &File{division: division}
+	tracks := make([]*Track, ntrks)
+	for i := range tracks {
+		tracks[i] = NewTrack()
+		f.AddTrack(tracks[i])
+	}
+	if err := f.SetFormat(format); err != nil {
+		return nil, err
+	}
+	for {
+		i, _, e, err := rd.Next()
+		if err == io.EOF {
+			for i, end := range rd.ends {
+				tracks[i].end = end
+			}
+			return f, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		tracks[i].AddEvent(e)
+	}
+}
+
+// decoder parses the chunks of a SMF from an underlying reader
+type decoder struct {
+	r io.ByteReader
+	// remaining bytes in the current chunk
+	remaining uint32
+	// running status of the current track
+	status byte
+	// whether the current track already reached its end
+	eot bool
+	// delta time of the end of track event of the current track
+	eotDelta int
+	// position of the decoder and of the current chunk
+	offset, chunkOffset int64
+	// index of the current track and event, -1 if none
+	track, event int
+}
+
+func newDecoder(r io.Reader) *decoder {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &decoder{r: br, track: -1, event: -1}
+}
+
+// wrap annotates err with the current position of the decoder
+func (d *decoder) wrap(err error) error {
+	return &DecodeError{
+		Offset:      d.offset,
+		ChunkOffset: d.chunkOffset,
+		Track:       d.track,
+		Event:       d.event,
+		Err:         err,
+	}
+}
+
+// readChunkHeader reads the id and length of the next chunk
+func (d *decoder) readChunkHeader() (string, uint32, error) {
+	var hdr [8]byte
+	d.chunkOffset = d.offset
+	for i := range hdr {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", 0, err
+		}
+		hdr[i] = b
+		d.offset++
+	}
+	d.remaining = binary.BigEndian.Uint32(hdr[4:])
+	return string(hdr[:4]), d.remaining, nil
+}
+
+// skipChunk discards whatever is left of the current chunk
+func (d *decoder) skipChunk() error {
+	for d.remaining > 0 {
+		if _, err := d.readByte(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readHeader reads the MThd chunk, returning the format,
+// the number of tracks and the time division
+func (d *decoder) readHeader() (Format, int, Division, error) {
+	id, length, err := d.readChunkHeader()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if id != FileHdrChunkID {
+		return 0, 0, 0, errors.New("not a midi file")
+	}
+	if length < 6 {
+		return 0, 0, 0, fmt.Errorf("invalid header length %d", length)
+	}
+	b, err := d.readBytes(6)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	format := Format(binary.BigEndian.Uint16(b))
+	ntrks := int(binary.BigEndian.Uint16(b[2:]))
+	division := Division(binary.BigEndian.Uint16(b[4:]))
+	if format > Format2 {
+		return 0, 0, 0, fmt.Errorf("unknown format %d", format)
+	}
+	if format == Format0 && ntrks != 1 {
+		return 0, 0, 0, fmt.Errorf("format 0 file with %d tracks", ntrks)
+	}
+	if err := division.validate(); err != nil {
+		return 0, 0, 0, err
+	}
+	// any extra header bytes are reserved for future use
+	if err := d.skipChunk(); err != nil {
+		return 0, 0, 0, err
+	}
+	return format, ntrks, division, nil
+}
+
+// nextTrack skips what is left of the current track and moves on to
+// the next MTrk chunk, ignoring any unknown chunk in between
+func (d *decoder) nextTrack() error {
+	if err := d.skipChunk(); err != nil {
+		return err
+	}
+	d.track++
+	d.event = -1
+	for {
+		id, _, err := d.readChunkHeader()
+		if err != nil {
+			return err
+		}
+		if id == TrackChunkID {
+			break
+		}
+		if err := d.skipChunk(); err != nil {
+			return err
+		}
+	}
+	d.status = 0
+	d.eot = false
+	d.eotDelta = 0
+	return nil
+}
+
+// readEvent reads the next event of the current track along with its
+// delta time, it returns a nil Event once the end of the track is reached
+func (d *decoder) readEvent() (int, Event, error) {
+	for !d.eot && d.remaining > 0 {
+		d.event++
+		delta, err := d.readVLQ()
+		if err != nil {
+			return 0, nil, err
+		}
+		status, err := d.readByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		var e Event
+		switch {
+		case status < 0x80:
+			// running status, the byte read is the first data byte
+			if d.status == 0 {
+				return 0, nil, errors.New("data byte without running status")
+			}
+			e, err = d.readNormalEvent(delta, d.status, &status)
+		case status < 0xF0:
+			d.status = status
+			e, err = d.readNormalEvent(delta, status, nil)
+		case status == 0xFF:
+			d.status = 0
+			e, err = d.readMetaEvent(delta)
+		case status == SysExStart || status == SysExEnd:
+			d.status = 0
+			e, err = d.readSysExEvent(delta, status == SysExEnd)
+		default:
+			return 0, nil, fmt.Errorf("invalid status byte 0x%02x", status)
+		}
+		if err != nil || e != nil {
+			return delta, e, err
+		}
+	}
+	return 0, nil, nil
+}
+
+// readNormalEvent reads the data bytes of a channel event, data1
+// is non-nil when it was already read because of running status
+func (d *decoder) readNormalEvent(delta int, status byte, data1 *byte) (Event, error) {
+	e := &NormalEvent{
+		delta:   delta,
+		_type:   EventType(status & 0xF0),
+		channel: int(status & 0x0F),
+	}
+	if data1 != nil {
+		e.param1 = *data1
+	} else {
+		b, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		e.param1 = b
+	}
+	if e._type.dataLen() == 2 {
+		b, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		e.param2 = b
+	}
+	if e.param1 > 0x7F || e.param2 > 0x7F {
+		return nil, errors.New("invalid data byte")
+	}
+	return e, nil
+}
+
+// readMetaEvent reads a meta event, returning a nil Event
+// for the end of track
+func (d *decoder) readMetaEvent(delta int) (Event, error) {
+	_type, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	length, err := d.readVLQ()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.readBytes(length)
+	if err != nil {
+		return nil, err
+	}
+	e := &MetaEvent{
+		delta: delta,
+		_type: MetaType(_type),
+		data:  data,
+	}
+	switch e._type {
+	case EventEndOfTrack:
+		// Track.Bytes adds its own end of track, after eotDelta
+		d.eot = true
+		d.eotDelta = delta
+		return nil, nil
+	case EventTempo:
+		if len(data) == 3 {
+			e.data = Timing(data[0])<<16 | Timing(data[1])<<8 | Timing(data[2])
+		}
+	}
+	return e, nil
+}
+
+// readSysExEvent reads a system exclusive event or continuation packet
+func (d *decoder) readSysExEvent(delta int, continuation bool) (Event, error) {
+	length, err := d.readVLQ()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.readBytes(length)
+	if err != nil {
+		return nil, err
+	}
+	return &SysExEvent{
+		delta:        delta,
+		continuation: continuation,
+		data:         data,
+	}, nil
+}
+
+// readByte reads a single byte of the current chunk
+func (d *decoder) readByte() (byte, error) {
+	if d.remaining == 0 {
+		return 0, errors.New("unexpected end of chunk")
+	}
+	b, err := d.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	d.remaining--
+	d.offset++
+	return b, nil
+}
+
+// readBytes reads n bytes of the current chunk
+func (d *decoder) readBytes(n int) ([]byte, error) {
+	if uint32(n) > d.remaining {
+		return nil, errors.New("unexpected end of chunk")
+	}
+	b := make([]byte, n)
+	for i := range b {
+		c, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		b[i] = c
+	}
+	return b, nil
+}
+
+// readVLQ reads a variable-length quantity of the current chunk
+func (d *decoder) readVLQ() (int, error) {
+	n := 0
+	for i := 0; i < maxVLQBytes; i++ {
+		b, err := d.readByte()
+		if err != nil {
+			return 0, err
+		}
+		n = n<<7 | int(b&0x7F)
+		if b&0x80 == 0 {
+			return n, nil
+		}
+	}
+	return 0, errors.New("invalid variable-length quantity")
+}
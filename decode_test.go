@@ -0,0 +1,317 @@
+package midi
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	header := func(format, ntrks, division byte) []byte {
+		return []byte{
+			0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
+			0x0, format, 0x0, ntrks, 0x0, division,
+		}
+	}
+	track := func(events ...byte) []byte {
+		return append([]byte{
+			0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, byte(len(events)),
+		}, events...)
+	}
+	file := func(chunks ...[]byte) []byte {
+		return bytes.Join(chunks, nil)
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		want    *File
+		wantErr bool
+	}{
+		{
+			"empty track",
+			file(header(0, 1, 0x60), track(0x0, 0xff, 0x2f, 0x0)),
+			&File{
+				division:  0x60,
+				tracks:    []*Track{{}},
+				formatSet: true,
+			},
+			false,
+		},
+		{
+			"events",
+			file(
+				header(0, 1, 0x80),
+				track(
+					0x0, 0xff, 0x3, 0x2, 0x68, 0x69,
+					0x0, 0xff, 0x51, 0x3, 0x7, 0xa1, 0x20,
+					0x0, 0xc2, 0x5,
+					0x0, 0x92, 0x3c, 0x5a,
+					0x81, 0x0, 0x3c, 0x0,
+					0x0, 0xff, 0x2f, 0x0,
+				),
+			),
+			&File{
+				division:  0x80,
+				formatSet: true,
+				tracks: []*Track{{
+					events: []Event{
+						&MetaEvent{_type: 0x3, data: []byte("hi")},
+						&MetaEvent{_type: EventTempo, data: Timing(500000)},
+						&NormalEvent{_type: EventProgramChange, channel: 2, param1: 0x5},
+						&NormalEvent{_type: EventNoteOn, channel: 2, param1: 0x3c, param2: 0x5a},
+						&NormalEvent{delta: 128, tick: 128, _type: EventNoteOn, channel: 2, param1: 0x3c},
+					},
+				}},
+			},
+			false,
+		},
+		{
+			"end of track delta",
+			file(
+				header(0, 1, 0x80),
+				track(
+					0x10, 0x90, 0x3c, 0x40,
+					0x60, 0xff, 0x2f, 0x0,
+				),
+			),
+			&File{
+				division:  0x80,
+				formatSet: true,
+				tracks: []*Track{{
+					events: []Event{
+						&NormalEvent{delta: 0x10, tick: 0x10, _type: EventNoteOn, param1: 0x3c, param2: 0x40},
+					},
+					end: 0x70,
+				}},
+			},
+			false,
+		},
+		{
+			"unknown chunks and sysex",
+			file(
+				header(1, 2, 0x80),
+				[]byte{0x58, 0x59, 0x5a, 0x5a, 0x0, 0x0, 0x0, 0x2, 0x1, 0x2},
+				track(
+					0x2, 0xf0, 0x2, 0x7e, 0xf7,
+					0x3, 0x80, 0x3c, 0x40,
+				),
+				track(0x0, 0xff, 0x2f, 0x0),
+			),
+			&File{
+				division:  0x80,
+				format:    Format1,
+				formatSet: true,
+				tracks: []*Track{
+					{
+						events: []Event{
+							&SysExEvent{delta: 2, tick: 2, data: []byte{0x7e, 0xf7}},
+							&NormalEvent{delta: 3, tick: 5, _type: EventNoteOff, param1: 0x3c, param2: 0x40},
+						},
+					},
+					{},
+				},
+			},
+			false,
+		},
+		{
+			"split sysex",
+			file(
+				header(0, 1, 0x80),
+				track(
+					0x0, 0xf0, 0x2, 0x43, 0x12,
+					0x60, 0xf7, 0x3, 0x0, 0x43, 0xf7,
+					0x0, 0xf7, 0x1, 0xfa,
+				),
+			),
+			&File{
+				division:  0x80,
+				formatSet: true,
+				tracks: []*Track{{
+					events: []Event{
+						&SysExEvent{data: []byte{0x43, 0x12}},
+						&SysExEvent{delta: 0x60, tick: 0x60, continuation: true, data: []byte{0x0, 0x43, 0xf7}},
+						&SysExEvent{tick: 0x60, continuation: true, data: []byte{0xfa}},
+					},
+				}},
+			},
+			false,
+		},
+		{
+			"format 2",
+			file(header(2, 2, 0x80), track(0x0, 0xff, 0x2f, 0x0), track(0x0, 0xff, 0x2f, 0x0)),
+			&File{
+				division:  0x80,
+				tracks:    []*Track{{}, {}},
+				format:    Format2,
+				formatSet: true,
+			},
+			false,
+		},
+		{
+			"unknown format",
+			file(header(3, 1, 0x80), track(0x0, 0xff, 0x2f, 0x0)),
+			nil,
+			true,
+		},
+		{
+			"format 0 with 2 tracks",
+			file(header(0, 2, 0x80), track(0x0, 0xff, 0x2f, 0x0), track(0x0, 0xff, 0x2f, 0x0)),
+			nil,
+			true,
+		},
+		{
+			"not a midi file",
+			[]byte("RIFF\x00\x00\x00\x06\x00\x00\x00\x01\x00\x80"),
+			nil,
+			true,
+		},
+		{
+			"truncated header",
+			[]byte{0x4d, 0x54, 0x68, 0x64, 0x0},
+			nil,
+			true,
+		},
+		{
+			"smpte division",
+			file([]byte{
+				0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
+				0x0, 0x0, 0x0, 0x1, 0xe7, 0x28,
+			}, track(0x0, 0xff, 0x2f, 0x0)),
+			&File{
+				division:  0xe728,
+				tracks:    []*Track{{}},
+				formatSet: true,
+			},
+			false,
+		},
+		{
+			"invalid smpte division",
+			file([]byte{
+				0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
+				0x0, 0x0, 0x0, 0x1, 0xe9, 0x28,
+			}, track(0x0, 0xff, 0x2f, 0x0)),
+			nil,
+			true,
+		},
+		{
+			"zero division",
+			file(header(0, 1, 0x0), track(0x0, 0xff, 0x2f, 0x0)),
+			nil,
+			true,
+		},
+		{
+			"missing track",
+			file(header(1, 2, 0x80), track(0x0, 0xff, 0x2f, 0x0)),
+			nil,
+			true,
+		},
+		{
+			"running status without status",
+			file(header(0, 1, 0x80), track(0x0, 0x3c, 0x40)),
+			nil,
+			true,
+		},
+		{
+			"truncated event",
+			file(header(0, 1, 0x80), track(0x0, 0x90, 0x3c)),
+			nil,
+			true,
+		},
+		{
+			"invalid status",
+			file(header(0, 1, 0x80), track(0x0, 0xf1, 0x0)),
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	data := []byte{
+		0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
+		0x0, 0x1, 0x0, 0x2, 0x0, 0x60,
+		0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x8,
+		0x0, 0x90, 0x3c, 0x40, 0x60, 0xff, 0x2f, 0x0,
+		0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x5,
+		0x81, 0x0, 0xff, 0x2f, 0x0,
+	}
+	f, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got := f.Bytes(); !reflect.DeepEqual(got, Codes(data)) {
+		t.Errorf("File.Bytes() = %v, want %v", got, Codes(data))
+	}
+	var w bytes.Buffer
+	if _, err := f.WriteTo(&w); err != nil || !bytes.Equal(w.Bytes(), data) {
+		t.Errorf("File.WriteTo() = %v, %v, want %v", Codes(w.Bytes()), err, Codes(data))
+	}
+}
+
+func TestDecode_EditTrack(t *testing.T) {
+	data := []byte{
+		0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
+		0x0, 0x0, 0x0, 0x1, 0x0, 0x60,
+		0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x8,
+		0x0, 0x90, 0x3c, 0x40, 0x60, 0xff, 0x2f, 0x0,
+	}
+	f, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	// an event before the end keeps the end where it was
+	tr := f.tracks[0].NoteOffAfter(0, Pitch(60), 5, 0)
+	want := Codes{
+		0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0xc,
+		0x0, 0x90, 0x3c, 0x40,
+		0x5, 0x80, 0x3c, 0x5a,
+		0x5b, 0xff, 0x2f, 0x0,
+	}
+	if got := tr.Bytes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want)
+	}
+	// an event after the end moves it
+	tr.TextAfter("late", 0x100)
+	if got := tr.Bytes(); !bytes.Equal(got[len(got)-4:], TrackEndBytes) {
+		t.Errorf("Track.Bytes() = %v, want it to end with %v", got, TrackEndBytes)
+	}
+}
+
+func TestDecodeError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		e    *DecodeError
+		want string
+	}{
+		{
+			"header error",
+			&DecodeError{Track: -1, Event: -1, Err: io.ErrUnexpectedEOF},
+			"midi: header (chunk at 0, byte 0): unexpected EOF",
+		},
+		{
+			"event error",
+			&DecodeError{Filename: "a.mid", Offset: 28, ChunkOffset: 14, Track: 0, Event: 1, Err: io.ErrUnexpectedEOF},
+			"midi: a.mid: track 0 event 1 (chunk at 14, byte 28): unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.Error(); got != tt.want {
+				t.Errorf("DecodeError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
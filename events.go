@@ -1,14 +1,36 @@
 package midi
 
-import "errors"
+import (
+	"errors"
+	"math"
+)
 
-// Event represents a midi event, let it be
-// MetaEvent or NormalEvent
+// Event represents a midi event, let it be MetaEvent, NormalEvent
+// or SysExEvent, or one of the typed events returned by Typed
 type Event interface {
+	// SetTime sets the time of the event in ticks since the previous event
 	SetTime(ticks int)
+	// Delta returns the time of the event in ticks since the previous event
+	Delta() int
+	// Tick returns the absolute time of the event in ticks, it is set
+	// when the event is added to a track or read from a file
+	Tick() int
 	Bytes() Codes
 }
 
+// ticker is implemented by the events of the package
+// so tracks and readers can set their absolute time
+type ticker interface {
+	setTick(tick int)
+}
+
+// setTick sets the absolute time of e, if it can be set
+func setTick(e Event, tick int) {
+	if t, ok := e.(ticker); ok {
+		t.setTick(tick)
+	}
+}
+
 // EventType is self explanatory
 type EventType byte
 
@@ -30,44 +52,59 @@ const (
 	EventChannelAfterTouch EventType = 0xD0
 	// EventPitchBend midi event
 	EventPitchBend EventType = 0xE0
+)
 
-	// MetaEvents
-
-	// EventSequence midi event
-	EventSequence EventType = iota
-	// EventText midi event
-	EventText
-	// EventCopyright midi event
-	EventCopyright
-	// EventTrackName midi event
-	EventTrackName
-	// EventInstrument midi event
-	EventInstrument
-	// EventLyric midi event
-	EventLyric
-	// EventMarker midi event
-	EventMarker
-	// EventCuePoint midi event
-	EventCuePoint
-	// EventChannelPrefix midi event
-	EventChannelPrefix EventType = 0x20
-	// EventEndOfTrack midi event
-	EventEndOfTrack EventType = 0x2f
-	// EventTempo midi event
-	EventTempo EventType = 0x51
-	// EventSmpte midi event
-	EventSmpte EventType = 0x54
-	// EventTimeSig midi event
-	EventTimeSig EventType = 0x58
-	// EventKeySig midi event
-	EventKeySig EventType = 0x59
-	// EventSeqEvent midi event
-	EventSeqEvent EventType = 0x7f
+// MetaType is the type of a meta event
+type MetaType byte
+
+const (
+	// EventSequence meta event
+	EventSequence MetaType = 0x00
+	// EventText meta event
+	EventText MetaType = 0x01
+	// EventCopyright meta event
+	EventCopyright MetaType = 0x02
+	// EventTrackName meta event
+	EventTrackName MetaType = 0x03
+	// EventInstrument meta event
+	EventInstrument MetaType = 0x04
+	// EventLyric meta event
+	EventLyric MetaType = 0x05
+	// EventMarker meta event
+	EventMarker MetaType = 0x06
+	// EventCuePoint meta event
+	EventCuePoint MetaType = 0x07
+	// EventChannelPrefix meta event
+	EventChannelPrefix MetaType = 0x20
+	// EventPortPrefix meta event
+	EventPortPrefix MetaType = 0x21
+	// EventEndOfTrack meta event
+	EventEndOfTrack MetaType = 0x2f
+	// EventTempo meta event
+	EventTempo MetaType = 0x51
+	// EventSmpte meta event
+	EventSmpte MetaType = 0x54
+	// EventTimeSig meta event
+	EventTimeSig MetaType = 0x58
+	// EventKeySig meta event
+	EventKeySig MetaType = 0x59
+	// EventSeqEvent meta event
+	EventSeqEvent MetaType = 0x7f
 )
 
+// dataLen returns the number of data bytes carried by
+// a channel event of type t
+func (t EventType) dataLen() int {
+	switch t {
+	case EventProgramChange, EventChannelAfterTouch:
+		return 1
+	}
+	return 2
+}
+
 // NormalEvent is a single midi event
 type NormalEvent struct {
-	time           []byte
+	delta, tick    int
 	_type          EventType
 	channel        int
 	param1, param2 byte
@@ -75,17 +112,23 @@ type NormalEvent struct {
 
 // NewEvent returns a new midi event
 func NewEvent(time []byte, _type EventType, channel int, param1, param2 byte) (*NormalEvent, error) {
+	return NewEventAfter(ParseTickTime(time), _type, channel, param1, param2)
+}
+
+// NewEventAfter returns a new midi event delta ticks after the previous one
+func NewEventAfter(delta int, _type EventType, channel int, param1, param2 byte) (*NormalEvent, error) {
+	if err := checkDelta(delta); err != nil {
+		return nil, err
+	}
 	if channel < 0 || channel > 15 {
 		return nil, errors.New("channel out of bounds")
 	}
-	if _type < EventNoteOff || _type > EventPitchBend {
+	// the channel goes in the low nibble of the status byte
+	if _type < EventNoteOff || _type > EventPitchBend || _type&0x0F != 0 {
 		return nil, errors.New("unknown event type")
 	}
-	if len(time) == 0 {
-		time = []byte{0}
-	}
 	return &NormalEvent{
-		time:    time,
+		delta:   delta,
 		_type:   _type,
 		channel: channel,
 		param1:  param1,
@@ -94,25 +137,65 @@ func NewEvent(time []byte, _type EventType, channel int, param1, param2 byte) (*
 }
 
 // SetTime sets the time for the event in ticks since the
-// previous event
+// previous event, negative times are set to 0
 func (e *NormalEvent) SetTime(ticks int) {
-	e.time = TranslateTickTime(ticks)
+	if ticks < 0 {
+		ticks = 0
+	}
+	e.delta = ticks
+}
+
+// Delta returns the time of the event in ticks since the previous event
+func (e *NormalEvent) Delta() int {
+	return e.delta
+}
+
+// Tick returns the absolute time of the event in ticks
+func (e *NormalEvent) Tick() int {
+	return e.tick
+}
+
+func (e *NormalEvent) setTick(tick int) {
+	e.tick = tick
+}
+
+// Type returns the type of the event
+func (e *NormalEvent) Type() EventType {
+	return e._type
+}
+
+// Channel returns the channel of the event
+func (e *NormalEvent) Channel() int {
+	return e.channel
+}
+
+// Data1 returns the first data byte of the event
+func (e *NormalEvent) Data1() byte {
+	return e.param1
+}
+
+// Data2 returns the second data byte of the event, events
+// with a single data byte always return 0
+func (e *NormalEvent) Data2() byte {
+	if e._type.dataLen() != 2 {
+		return 0
+	}
+	return e.param2
 }
 
 // Bytes returns the serielized event
 func (e *NormalEvent) Bytes() Codes {
-	typeChannel := e._type
-	if typeChannel == 0 {
-		typeChannel = EventType(e.channel & 0xF)
-	}
+	// the status byte holds the type in the high nibble
+	// and the channel in the low one
+	status := byte(e._type&0xF0) | byte(e.channel&0xF)
 
 	bytes := []byte{}
 
-	bytes = append(bytes, e.time...)
-	bytes = append(bytes, byte(typeChannel))
+	bytes = append(bytes, TranslateTickTime(e.delta)...)
+	bytes = append(bytes, status)
 	bytes = append(bytes, e.param1)
 
-	if e.param2 != 0 {
+	if e._type.dataLen() == 2 {
 		bytes = append(bytes, e.param2)
 	}
 
@@ -121,59 +204,286 @@ func (e *NormalEvent) Bytes() Codes {
 
 // MetaEvent is a single meta event on a midi file
 type MetaEvent struct {
-	time  []byte
-	_type EventType
+	delta int
+	tick  int
+	_type MetaType
 	// data must be a string or []byte
 	data interface{}
 }
 
 // NewMetaEvent returns a new meta event, data must be string, []byte or Timing
-func NewMetaEvent(time []byte, _type EventType, data interface{}) (*MetaEvent, error) {
-	switch data.(type) {
-	case string, []byte, Timing:
+func NewMetaEvent(time []byte, _type MetaType, data interface{}) (*MetaEvent, error) {
+	return NewMetaEventAfter(ParseTickTime(time), _type, data)
+}
+
+// NewMetaEventAfter returns a new meta event delta ticks after the
+// previous one, data must be string, []byte or Timing
+func NewMetaEventAfter(delta int, _type MetaType, data interface{}) (*MetaEvent, error) {
+	if err := checkDelta(delta); err != nil {
+		return nil, err
+	}
+	switch v := data.(type) {
+	case string:
+		if len(v) > MaxVLQ {
+			return nil, errors.New("data too long")
+		}
+	case []byte:
+		if len(v) > MaxVLQ {
+			return nil, errors.New("data too long")
+		}
+	case Timing:
+		// only set tempo events hold a timing
+		if _type != EventTempo {
+			return nil, errors.New("timing data for a non-tempo meta event")
+		}
+		if v < 0 || v > MaxMpqn {
+			return nil, errors.New("timing out of range")
+		}
 	default:
 		return nil, errors.New("invalid data type")
 	}
 	switch _type {
 	case EventSequence, EventText, EventCopyright, EventTrackName, EventInstrument,
-		EventLyric, EventMarker, EventCuePoint, EventChannelPrefix, EventEndOfTrack,
+		EventLyric, EventMarker, EventCuePoint, EventChannelPrefix, EventPortPrefix, EventEndOfTrack,
 		EventTempo, EventSmpte, EventTimeSig, EventKeySig, EventSeqEvent:
 	default:
 		return nil, errors.New("invalid meta type")
 	}
-	if len(time) == 0 {
-		time = []byte{0}
-	}
 	return &MetaEvent{
-		time:  time,
+		delta: delta,
 		_type: _type,
 		data:  data,
 	}, nil
 }
 
+// NewTempoEvent returns a new set tempo meta event
+// time - The number of ticks since the previous event, default is 0
+// bpm  - The beats per minute, stored as microseconds per quarter note
+func NewTempoEvent(time []byte, bpm float64) (*MetaEvent, error) {
+	return NewTempoEventAfter(ParseTickTime(time), bpm)
+}
+
+// NewTempoEventAfter returns a new set tempo meta event
+// delta - The number of ticks since the previous event
+// bpm   - The beats per minute, stored as microseconds per quarter note
+func NewTempoEventAfter(delta int, bpm float64) (*MetaEvent, error) {
+	if math.IsNaN(bpm) || bpm < MinBpm || bpm > MaxBpm {
+		return nil, errors.New("tempo out of range")
+	}
+	mpqn := Timing(math.Floor(MicrosecondsPerMinute/bpm + 0.5))
+	if mpqn > MaxMpqn {
+		mpqn = MaxMpqn
+	}
+	return NewMetaEventAfter(delta, EventTempo, mpqn)
+}
+
+// tempoOf returns the microseconds per quarter note of e
+// if it is a set tempo meta event
+func tempoOf(e Event) (Timing, bool) {
+	m, ok := e.(*MetaEvent)
+	if !ok || m._type != EventTempo {
+		return 0, false
+	}
+	switch v := m.data.(type) {
+	case Timing:
+		return v, true
+	case []byte:
+		if len(v) == 3 {
+			return Timing(v[0])<<16 | Timing(v[1])<<8 | Timing(v[2]), true
+		}
+	}
+	return 0, false
+}
+
 // SetTime sets the time for the event in ticks since the
-// previous event
+// previous event, negative times are set to 0
 func (e *MetaEvent) SetTime(ticks int) {
-	e.time = TranslateTickTime(ticks)
+	if ticks < 0 {
+		ticks = 0
+	}
+	e.delta = ticks
+}
+
+// Delta returns the time of the event in ticks since the previous event
+func (e *MetaEvent) Delta() int {
+	return e.delta
+}
+
+// Tick returns the absolute time of the event in ticks
+func (e *MetaEvent) Tick() int {
+	return e.tick
+}
+
+func (e *MetaEvent) setTick(tick int) {
+	e.tick = tick
+}
+
+// MetaType returns the type of the meta event
+func (e *MetaEvent) MetaType() MetaType {
+	return e._type
+}
+
+// Payload returns a copy of the data of the event
+// as it is written after its length
+func (e *MetaEvent) Payload() []byte {
+	switch v := e.data.(type) {
+	case []byte:
+		return append([]byte{}, v...)
+	case string:
+		return []byte(v)
+	case Timing:
+		return []byte{byte(v >> 16), byte(v >> 8), byte(v)}
+	}
+	return []byte{}
 }
 
 // Bytes returns the serielized event
 func (e *MetaEvent) Bytes() Codes {
 	bytes := []byte{}
 
-	bytes = append(bytes, e.time...)
+	bytes = append(bytes, TranslateTickTime(e.delta)...)
 	bytes = append(bytes, byte(0xFF), byte(e._type))
 	if v, ok := e.data.([]byte); ok {
-		bytes = append(bytes, byte(len(v)))
+		bytes = append(bytes, TranslateTickTime(len(v))...)
 		bytes = append(bytes, v...)
 	} else if v, ok := e.data.(string); ok {
-		bytes = append(bytes, byte(len(v)))
+		bytes = append(bytes, TranslateTickTime(len(v))...)
 		bytes = append(bytes, []byte(v)...)
 	} else if v, ok := e.data.(Timing); ok {
-		bytes = append(bytes, 0x1, byte(v))
+		bytes = append(bytes, 0x3, byte(v>>16), byte(v>>8), byte(v))
 	} else {
 		bytes = append(bytes, 0)
 	}
 
 	return Codes(bytes)
 }
+
+const (
+	// SysExStart is the status byte of a system exclusive message
+	SysExStart = 0xF0
+	// SysExEnd is the last byte of a system exclusive message, it is also
+	// the status byte of continuation packets
+	SysExEnd = 0xF7
+)
+
+var (
+	// GMReset is the GM System On message
+	GMReset = Codes{0x7E, 0x7F, 0x09, 0x01, 0xF7}
+	// GSReset is the Roland GS Reset message
+	GSReset = Codes{0x41, 0x10, 0x42, 0x12, 0x40, 0x00, 0x7F, 0x00, 0x41, 0xF7}
+	// XGReset is the Yamaha XG System On message
+	XGReset = Codes{0x43, 0x10, 0x4C, 0x00, 0x00, 0x7E, 0x00, 0xF7}
+)
+
+// SysExEvent is a system exclusive event, either a complete
+// message, the first packet of a message split across several
+// events or one of its continuation packets
+type SysExEvent struct {
+	delta, tick  int
+	continuation bool
+	data         []byte
+}
+
+// NewSysExEvent returns a new system exclusive event
+// time - The number of ticks since the previous event, default is 0
+// data - The message without its leading SysExStart, ends with SysExEnd
+// unless the message continues in continuation packets
+func NewSysExEvent(time []byte, data []byte) (*SysExEvent, error) {
+	return NewSysExEventAfter(ParseTickTime(time), data)
+}
+
+// NewSysExEventAfter returns a new system exclusive event
+// delta - The number of ticks since the previous event
+// data  - The message, see NewSysExEvent
+func NewSysExEventAfter(delta int, data []byte) (*SysExEvent, error) {
+	if len(data) > 0 && data[0] == SysExStart {
+		data = data[1:]
+	}
+	for i, b := range data {
+		if b > 0x7F && (b != SysExEnd || i != len(data)-1) {
+			return nil, errors.New("invalid sysex data byte")
+		}
+	}
+	return newSysExEvent(delta, false, data)
+}
+
+// NewSysExContinuation returns a continuation packet of a system exclusive
+// message started by NewSysExEvent, it can also be used to send arbitrary
+// bytes, such as real time messages
+// time - The number of ticks since the previous event, default is 0
+// data - The packet, the last packet of a message ends with SysExEnd
+func NewSysExContinuation(time []byte, data []byte) (*SysExEvent, error) {
+	return newSysExEvent(ParseTickTime(time), true, data)
+}
+
+// NewSysExContinuationAfter returns a continuation packet of a system
+// exclusive message
+// delta - The number of ticks since the previous event
+// data  - The packet, see NewSysExContinuation
+func NewSysExContinuationAfter(delta int, data []byte) (*SysExEvent, error) {
+	return newSysExEvent(delta, true, data)
+}
+
+func newSysExEvent(delta int, continuation bool, data []byte) (*SysExEvent, error) {
+	if err := checkDelta(delta); err != nil {
+		return nil, err
+	}
+	if len(data) > MaxVLQ {
+		return nil, errors.New("data too long")
+	}
+	return &SysExEvent{
+		delta:        delta,
+		continuation: continuation,
+		data:         data,
+	}, nil
+}
+
+// SetTime sets the time for the event in ticks since the
+// previous event, negative times are set to 0
+func (e *SysExEvent) SetTime(ticks int) {
+	if ticks < 0 {
+		ticks = 0
+	}
+	e.delta = ticks
+}
+
+// Delta returns the time of the event in ticks since the previous event
+func (e *SysExEvent) Delta() int {
+	return e.delta
+}
+
+// Tick returns the absolute time of the event in ticks
+func (e *SysExEvent) Tick() int {
+	return e.tick
+}
+
+func (e *SysExEvent) setTick(tick int) {
+	e.tick = tick
+}
+
+// Continuation returns whether the event is a continuation packet
+func (e *SysExEvent) Continuation() bool {
+	return e.continuation
+}
+
+// Payload returns a copy of the data of the event,
+// without the leading SysExStart
+func (e *SysExEvent) Payload() []byte {
+	return append([]byte{}, e.data...)
+}
+
+// Bytes returns the serielized event
+func (e *SysExEvent) Bytes() Codes {
+	bytes := []byte{}
+
+	bytes = append(bytes, TranslateTickTime(e.delta)...)
+	if e.continuation {
+		bytes = append(bytes, SysExEnd)
+	} else {
+		bytes = append(bytes, SysExStart)
+	}
+	bytes = append(bytes, TranslateTickTime(len(e.data))...)
+	bytes = append(bytes, e.data...)
+
+	return Codes(bytes)
+}
@@ -31,6 +31,12 @@ func TestNewEvent(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"event type with a channel",
+			args{nil, EventType(0xC5), 0, 1, 2},
+			nil,
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -46,6 +52,52 @@ func TestNewEvent(t *testing.T) {
 	}
 }
 
+func TestNewEventAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		delta   int
+		want    *NormalEvent
+		wantErr bool
+	}{
+		{
+			"delta",
+			96,
+			&NormalEvent{delta: 96, _type: EventNoteOn, param1: 60, param2: 90},
+			false,
+		},
+		{
+			"negative delta",
+			-1,
+			nil,
+			true,
+		},
+		{
+			"delta out of bounds",
+			MaxVLQ + 1,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEventAfter(tt.delta, EventNoteOn, 0, 60, 90)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEventAfter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewEventAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := NewMetaEventAfter(-1, EventText, "text"); err == nil {
+		t.Errorf("NewMetaEventAfter() error = nil, want error")
+	}
+	if _, err := NewSysExEventAfter(-1, GMReset); err == nil {
+		t.Errorf("NewSysExEventAfter() error = nil, want error")
+	}
+}
+
 func TestNormalEvent_SetTime(t *testing.T) {
 	type args struct {
 		ticks int
@@ -54,16 +106,27 @@ func TestNormalEvent_SetTime(t *testing.T) {
 		name string
 		e    *NormalEvent
 		args args
+		want int
 	}{
 		{
 			"set time",
 			&NormalEvent{},
 			args{128},
+			128,
+		},
+		{
+			"negative time",
+			&NormalEvent{delta: 10},
+			args{-1},
+			0,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.e.SetTime(tt.args.ticks)
+			if got := tt.e.Delta(); got != tt.want {
+				t.Errorf("NormalEvent.Delta() = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
@@ -71,13 +134,17 @@ func TestNormalEvent_SetTime(t *testing.T) {
 func TestNormalEvent_Bytes(t *testing.T) {
 	e := func(t EventType) *NormalEvent {
 		return &NormalEvent{
-			time:    []byte{0},
 			_type:   t,
 			channel: 0,
 			param1:  60,
 			param2:  90,
 		}
 	}
+	e2 := func(t EventType, param2 byte) *NormalEvent {
+		ev := e(t)
+		ev.param2 = param2
+		return ev
+	}
 	tests := []struct {
 		name string
 		e    *NormalEvent
@@ -93,6 +160,31 @@ func TestNormalEvent_Bytes(t *testing.T) {
 			e(0),
 			Codes{0x0, 0x0, 0x3c, 0x5a},
 		},
+		{
+			"note-on with 0 velocity",
+			e2(EventNoteOn, 0),
+			Codes{0x0, 0x90, 0x3c, 0x0},
+		},
+		{
+			"controller with 0 value",
+			e2(EventController, 0),
+			Codes{0x0, 0xb0, 0x3c, 0x0},
+		},
+		{
+			"pitch bend with 0 msb",
+			e2(EventPitchBend, 0),
+			Codes{0x0, 0xe0, 0x3c, 0x0},
+		},
+		{
+			"program change",
+			e(EventProgramChange),
+			Codes{0x0, 0xc0, 0x3c},
+		},
+		{
+			"channel after touch",
+			e(EventChannelAfterTouch),
+			Codes{0x0, 0xd0, 0x3c},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -103,10 +195,34 @@ func TestNormalEvent_Bytes(t *testing.T) {
 	}
 }
 
+func TestNormalEvent_BytesChannel(t *testing.T) {
+	types := []EventType{
+		EventNoteOff,
+		EventNoteOn,
+		EventAfterTouch,
+		EventController,
+		EventProgramChange,
+		EventChannelAfterTouch,
+		EventPitchBend,
+	}
+	for _, _type := range types {
+		for channel := 0; channel < 16; channel++ {
+			e, err := NewEvent(nil, _type, channel, 60, 90)
+			if err != nil {
+				t.Fatalf("NewEvent() error = %v", err)
+			}
+			want := byte(_type) | byte(channel)
+			if got := e.Bytes()[1]; got != want {
+				t.Errorf("NormalEvent.Bytes() status = %#x, want %#x", got, want)
+			}
+		}
+	}
+}
+
 func TestNewMetaEvent(t *testing.T) {
 	type args struct {
 		time  []byte
-		_type EventType
+		_type MetaType
 		data  interface{}
 	}
 	tests := []struct {
@@ -127,6 +243,24 @@ func TestNewMetaEvent(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"text event",
+			args{nil, 0x01, "hi"},
+			&MetaEvent{_type: EventText, data: "hi"},
+			false,
+		},
+		{
+			"tempo event",
+			args{nil, EventTempo, Timing(500000)},
+			&MetaEvent{_type: EventTempo, data: Timing(500000)},
+			false,
+		},
+		{
+			"timing for a text event",
+			args{nil, EventText, Timing(5)},
+			nil,
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -142,6 +276,71 @@ func TestNewMetaEvent(t *testing.T) {
 	}
 }
 
+func TestNewTempoEvent(t *testing.T) {
+	type args struct {
+		time []byte
+		bpm  float64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"120 bpm",
+			args{nil, 120},
+			Codes{0x0, 0xff, 0x51, 0x3, 0x7, 0xa1, 0x20},
+			false,
+		},
+		{
+			"slowest tempo",
+			args{nil, MinBpm},
+			Codes{0x0, 0xff, 0x51, 0x3, 0xff, 0xff, 0xff},
+			false,
+		},
+		{
+			"fastest tempo",
+			args{[]byte{0x60}, MaxBpm},
+			Codes{0x60, 0xff, 0x51, 0x3, 0x0, 0x0, 0x1},
+			false,
+		},
+		{
+			"too slow",
+			args{nil, 3},
+			nil,
+			true,
+		},
+		{
+			"zero tempo",
+			args{nil, 0},
+			nil,
+			true,
+		},
+		{
+			"too fast",
+			args{nil, MaxBpm + 1},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTempoEvent(tt.args.time, tt.args.bpm)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTempoEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if b := got.Bytes(); !reflect.DeepEqual(b, tt.want) {
+				t.Errorf("NewTempoEvent() = %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
+
 func TestMetaEvent_SetTime(t *testing.T) {
 	type args struct {
 		ticks int
@@ -169,7 +368,6 @@ func TestMetaEvent_Bytes(t *testing.T) {
 		return &MetaEvent{
 			data:  d,
 			_type: EventSequence,
-			time:  []byte{0},
 		}
 	}
 	tests := []struct {
@@ -180,22 +378,27 @@ func TestMetaEvent_Bytes(t *testing.T) {
 		{
 			"string data",
 			e("2"),
-			Codes{0x0, 0xFF, 0x7, 0x1, 0x32},
+			Codes{0x0, 0xFF, 0x0, 0x1, 0x32},
 		},
 		{
 			"timing data",
 			e(Timing(2)),
-			Codes{0x0, 0xFF, 0x7, 0x1, 0x2},
+			Codes{0x0, 0xFF, 0x0, 0x3, 0x0, 0x0, 0x2},
 		},
 		{
 			"bytes data",
 			e([]byte{0x2}),
-			Codes{0x0, 0xFF, 0x7, 0x1, 0x2},
+			Codes{0x0, 0xFF, 0x0, 0x1, 0x2},
 		},
 		{
 			"unknow data",
 			e(1),
-			Codes{0x0, 0xFF, 0x7, 0x0},
+			Codes{0x0, 0xFF, 0x0, 0x0},
+		},
+		{
+			"long data",
+			e(make([]byte, 300)),
+			append(Codes{0x0, 0xFF, 0x0, 0x82, 0x2c}, make([]byte, 300)...),
 		},
 	}
 	for _, tt := range tests {
@@ -206,3 +409,107 @@ func TestMetaEvent_Bytes(t *testing.T) {
 		})
 	}
 }
+
+func TestNewSysExEvent(t *testing.T) {
+	type args struct {
+		time []byte
+		data []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *SysExEvent
+		wantErr bool
+	}{
+		{
+			"gm reset",
+			args{nil, GMReset},
+			&SysExEvent{data: GMReset},
+			false,
+		},
+		{
+			"leading sysex start",
+			args{nil, []byte{0xf0, 0x7e, 0xf7}},
+			&SysExEvent{data: []byte{0x7e, 0xf7}},
+			false,
+		},
+		{
+			"first packet",
+			args{[]byte{0x10}, []byte{0x43, 0x12}},
+			&SysExEvent{delta: 0x10, data: []byte{0x43, 0x12}},
+			false,
+		},
+		{
+			"invalid data byte",
+			args{nil, []byte{0x43, 0x90, 0xf7}},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSysExEvent(tt.args.time, tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSysExEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSysExEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSysExContinuation(t *testing.T) {
+	got, err := NewSysExContinuation(nil, []byte{0xfa})
+	if err != nil {
+		t.Fatalf("NewSysExContinuation() error = %v", err)
+	}
+	want := &SysExEvent{continuation: true, data: []byte{0xfa}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewSysExContinuation() = %v, want %v", got, want)
+	}
+}
+
+func TestSysExEvent_Bytes(t *testing.T) {
+	tests := []struct {
+		name string
+		e    *SysExEvent
+		want Codes
+	}{
+		{
+			"complete message",
+			&SysExEvent{data: GMReset},
+			Codes{0x0, 0xf0, 0x5, 0x7e, 0x7f, 0x9, 0x1, 0xf7},
+		},
+		{
+			"continuation packet",
+			&SysExEvent{delta: 0x60, continuation: true, data: []byte{0x1, 0xf7}},
+			Codes{0x60, 0xf7, 0x2, 0x1, 0xf7},
+		},
+		{
+			"long message",
+			&SysExEvent{data: make([]byte, 200)},
+			append(Codes{0x0, 0xf0, 0x81, 0x48}, make([]byte, 200)...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.Bytes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SysExEvent.Bytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSysExEvent_SetTime(t *testing.T) {
+	e := &SysExEvent{}
+	e.SetTime(128)
+	if e.Delta() != 128 || !reflect.DeepEqual(e.Bytes()[:2], Codes{0x81, 0x0}) {
+		t.Errorf("SysExEvent.SetTime() = %v", e.Bytes())
+	}
+	e.SetTime(-128)
+	if e.Delta() != 0 || e.Bytes()[0] != 0x0 {
+		t.Errorf("SysExEvent.SetTime(-128) = %v", e.Bytes())
+	}
+}
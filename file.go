@@ -1,15 +1,19 @@
 package midi
 
 import (
+	"bufio"
 	"errors"
 	"io"
 	"os"
+	"sort"
 	"strings"
 )
 
 const (
 	// DefaultTicks for a midi file
 	DefaultTicks = 128
+	// MaxTracks is the maximum number of tracks of a midi file
+	MaxTracks = 0xFFFF
 	// FileHdrChunkID is the file magic cookie
 	FileHdrChunkID = "MThd"
 )
@@ -21,12 +25,29 @@ var (
 	FileHdrType0 = Codes{0x00, 0x00}
 	// FileHdrType1 is Midi Type 1 id
 	FileHdrType1 = Codes{0x00, 0x01}
+	// FileHdrType2 is Midi Type 2 id
+	FileHdrType2 = Codes{0x00, 0x02}
+)
+
+// Format is the SMF format of a midi file
+type Format uint16
+
+const (
+	// Format0 files have a single multi-channel track
+	Format0 Format = iota
+	// Format1 files have one or more simultaneous tracks
+	Format1
+	// Format2 files have one or more independent single-track patterns
+	Format2
 )
 
 // File is a midi file
 type File struct {
-	ticks  int
-	tracks []*Track
+	division Division
+	tracks   []*Track
+	// format is only used when formatSet is true
+	format    Format
+	formatSet bool
 }
 
 // NewFile returns a new midi file
@@ -35,42 +56,120 @@ func NewFile(ticks int, tracks ...*Track) (*File, error) {
 	if ticks == 0 {
 		ticks = DefaultTicks
 	}
-	if ticks < 0 || ticks >= (1<<15) || ticks%1 != 0 {
-		return nil, errors.New("ticks per beat must be an integer between 1 and 32767")
+	division, err := MetricalDivision(ticks)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracks) > MaxTracks {
+		return nil, errors.New("too many tracks")
 	}
 	return &File{
-		ticks:  ticks,
-		tracks: tracks,
+		division: division,
+		tracks:   tracks,
 	}, nil
 }
 
-// AddTrack adds a track to the file
-func (f *File) AddTrack(t *Track) {
+// Division returns the time division of the file
+func (f *File) Division() Division {
+	return f.division
+}
+
+// SetDivision sets the time division of the file, which can be
+// either metrical or timecode
+func (f *File) SetDivision(d Division) error {
+	if err := d.validate(); err != nil {
+		return err
+	}
+	f.division = d
+	return nil
+}
+
+// TickToSeconds converts an absolute time in ticks to seconds. Timecode
+// divisions have a fixed number of ticks per second, metrical ones follow
+// the set tempo events of every track, starting at 120 BPM
+func (f *File) TickToSeconds(tick int) float64 {
+	if f.division.IsSMPTE() {
+		return float64(tick) / (f.division.FramesPerSecond() * float64(f.division.TicksPerFrame()))
+	}
+	tempos := []timedEvent{}
+	for _, t := range f.tracks {
+		for _, te := range t.timeline() {
+			if _, ok := tempoOf(te.e); ok {
+				tempos = append(tempos, te)
+			}
+		}
+	}
+	sort.Stable(byTick(tempos))
+
+	// microseconds per tick at the current tempo
+	ticksPerBeat := float64(f.division.TicksPerBeat())
+	usPerTick := DefaultMpqn / ticksPerBeat
+	us, last := 0.0, 0
+	for _, te := range tempos {
+		if te.tick >= tick {
+			break
+		}
+		us += float64(te.tick-last) * usPerTick
+		mpqn, _ := tempoOf(te.e)
+		usPerTick = float64(mpqn) / ticksPerBeat
+		last = te.tick
+	}
+	us += float64(tick-last) * usPerTick
+	return us / 1e6
+}
+
+// AddTrack adds a track to the file, format 0 files
+// can't have more than one track
+func (f *File) AddTrack(t *Track) error {
+	if len(f.tracks) >= MaxTracks {
+		return errors.New("too many tracks")
+	}
+	if f.formatSet && f.format == Format0 && len(f.tracks) > 0 {
+		return errors.New("format 0 files must have exactly one track")
+	}
 	if t != nil {
 		f.tracks = append(f.tracks, t)
 	} else {
 		f.tracks = append(f.tracks, NewTrack())
 	}
+	return nil
 }
 
-// Bytes returns the serialized file
-func (f *File) Bytes() Codes {
-	trackCount := byte(len(f.tracks))
-
-	// prepare the file header
-	bytes := append(Codes(FileHdrChunkID), FileHdrChunkSize...)
+// Format returns the format of the file, unless set with SetFormat
+// it is Format0 for a single track and Format1 otherwise
+func (f *File) Format() Format {
+	if f.formatSet {
+		return f.format
+	}
+	if len(f.tracks) == 1 {
+		return Format0
+	}
+	return Format1
+}
 
-	// set Midi type based on number of tracks
-	if trackCount > 1 {
-		bytes = append(bytes, FileHdrType1...)
-	} else {
-		bytes = append(bytes, FileHdrType0...)
+// SetFormat sets the format of the file, Format0
+// files must have exactly one track
+func (f *File) SetFormat(format Format) error {
+	if format > Format2 {
+		return errors.New("unknown format")
+	}
+	if format == Format0 && len(f.tracks) > 1 {
+		return errors.New("format 0 files must have exactly one track")
 	}
+	f.format = format
+	f.formatSet = true
+	return nil
+}
 
-	// add the number of tracks (2 bytes)
-	bytes = append(bytes, GetCodes(string(trackCount), 2)...)
-	// add the number of ticks per beat
-	bytes = append(bytes, byte(f.ticks/256), byte(f.ticks%256))
+// Bytes returns the serialized file, or nil if it has more than
+// MaxTracks tracks or more than one track in format 0, use Encode
+// to get an error instead. The events not added to a track because
+// of an error are missing, so check the Err of every track first
+func (f *File) Bytes() Codes {
+	bytes, err := f.header()
+	if err != nil {
+		return nil
+	}
 
 	// iterate over the tracks, converting to bytes too
 	for _, track := range f.tracks {
@@ -80,6 +179,57 @@ func (f *File) Bytes() Codes {
 	return bytes
 }
 
+// WriteTo writes the serialized file to w, streaming each track
+// instead of serializing the whole file first
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	header, err := f.header()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(header)
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	for _, track := range f.tracks {
+		n, err := track.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// header returns the serialized file header
+func (f *File) header() (Codes, error) {
+	trackCount := len(f.tracks)
+	if trackCount > MaxTracks {
+		return nil, errors.New("too many tracks")
+	}
+	if f.Format() == Format0 && trackCount != 1 {
+		return nil, errors.New("format 0 files must have exactly one track")
+	}
+
+	// prepare the file header
+	bytes := append(Codes(FileHdrChunkID), FileHdrChunkSize...)
+
+	// set Midi type
+	switch f.Format() {
+	case Format0:
+		bytes = append(bytes, FileHdrType0...)
+	case Format1:
+		bytes = append(bytes, FileHdrType1...)
+	case Format2:
+		bytes = append(bytes, FileHdrType2...)
+	}
+
+	// add the number of tracks (2 bytes)
+	bytes = append(bytes, byte(trackCount>>8), byte(trackCount))
+	// add the time division
+	return append(bytes, byte(f.division>>8), byte(f.division)), nil
+}
+
 // Save will save the midi file under filename
 func (f *File) Save(filename string) error {
 	if !strings.HasSuffix(filename, ".mid") {
@@ -89,16 +239,40 @@ func (f *File) Save(filename string) error {
 	if err != nil {
 		return err
 	}
-	_, err = ff.Write(f.Bytes())
-	if err != nil {
+	w := bufio.NewWriter(ff)
+	if _, err := f.WriteTo(w); err != nil {
+		ff.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		ff.Close()
 		return err
 	}
 	return ff.Close()
 }
 
+// Load will load the midi file under filename, which is opened as it
+// is given, malformed files are reported with a *DecodeError
+func Load(filename string) (*File, error) {
+	ff, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer ff.Close()
+	f, err := Decode(ff)
+	if err != nil {
+		if e, ok := err.(*DecodeError); ok {
+			e.Filename = filename
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
 // Encode writes f to w
 func (f *File) Encode(w io.Writer) (int, error) {
-	return w.Write(f.Bytes())
+	n, err := f.WriteTo(w)
+	return int(n), err
 }
 
 // Encode writes f to w
@@ -2,6 +2,10 @@ package midi
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -21,7 +25,7 @@ func TestNewFile(t *testing.T) {
 			"default ticks",
 			args{0, nil},
 			&File{
-				ticks: DefaultTicks,
+				division: DefaultTicks,
 			},
 			false,
 		},
@@ -31,6 +35,12 @@ func TestNewFile(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"too many tracks",
+			args{0, make([]*Track, MaxTracks+1)},
+			nil,
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -99,11 +109,12 @@ func TestFile_Bytes(t *testing.T) {
 			f,
 			Codes{
 				0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
-				0x0, 0x0, 0x0, 0x80, 0x4d, 0x54, 0x72, 0x6b,
-				0x0, 0x0, 0x0, 0x29, 0x0, 0x90, 0x3c, 0x5a,
-				0x0, 0x90, 0x48, 0x5a, 0x0, 0x90, 0x30, 0x5a,
-				0x80, 0x4, 0x80, 0x48, 0x5a, 0x0, 0x80, 0x30,
-				0x5a, 0x0, 0x80, 0x24, 0x5a, 0x0, 0xff, 0x2f, 0x0,
+				0x0, 0x0, 0x0, 0x1, 0x0, 0x80, 0x4d, 0x54,
+				0x72, 0x6b, 0x0, 0x0, 0x0, 0x1c, 0x0, 0x90,
+				0x3c, 0x5a, 0x0, 0x92, 0x48, 0x5a, 0x0, 0x92,
+				0x30, 0x5a, 0x4, 0x82, 0x48, 0x5a, 0x0, 0x82,
+				0x30, 0x5a, 0x0, 0x83, 0x24, 0x5a, 0x0, 0xff,
+				0x2f, 0x0,
 			},
 		},
 		{
@@ -111,10 +122,10 @@ func TestFile_Bytes(t *testing.T) {
 			f2,
 			Codes{
 				0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
-				0x0, 0x1, 0x0, 0x80, 0x4d, 0x54, 0x72, 0x6b,
-				0x0, 0x0, 0x0, 0x4, 0x0, 0xff, 0x2f, 0x0,
-				0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x4,
-				0x0, 0xff, 0x2f, 0x0,
+				0x0, 0x1, 0x0, 0x2, 0x0, 0x80, 0x4d, 0x54,
+				0x72, 0x6b, 0x0, 0x0, 0x0, 0x4, 0x0, 0xff,
+				0x2f, 0x0, 0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0,
+				0x0, 0x4, 0x0, 0xff, 0x2f, 0x0,
 			},
 		},
 	}
@@ -170,8 +181,8 @@ func TestFile_Save(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			f := &File{
-				ticks:  tt.fields.ticks,
-				tracks: tt.fields.tracks,
+				division: Division(tt.fields.ticks),
+				tracks:   tt.fields.tracks,
 			}
 			if err := f.Save(tt.args.filename); (err != nil) != tt.wantErr {
 				t.Errorf("File.Save() error = %v, wantErr %v", err, tt.wantErr)
@@ -208,7 +219,7 @@ func TestFile_Encode(t *testing.T) {
 		{
 			"file.encode",
 			fields{DefaultTicks, []*Track{tr}},
-			49,
+			50,
 			string(f.Bytes()),
 			false,
 		},
@@ -216,8 +227,8 @@ func TestFile_Encode(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			f := &File{
-				ticks:  tt.fields.ticks,
-				tracks: tt.fields.tracks,
+				division: Division(tt.fields.ticks),
+				tracks:   tt.fields.tracks,
 			}
 			w := &bytes.Buffer{}
 			got, err := f.Encode(w)
@@ -262,7 +273,7 @@ func TestEncode(t *testing.T) {
 		{
 			"encode",
 			args{f},
-			49,
+			50,
 			string(f.Bytes()),
 			false,
 		},
@@ -284,3 +295,442 @@ func TestEncode(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    *File
+		wantErr error
+	}{
+		{
+			"load file",
+			"testdata/simple.mid",
+			&File{
+				division:  DefaultTicks,
+				formatSet: true,
+				tracks: []*Track{{
+					events: []Event{
+						&NormalEvent{_type: EventProgramChange, param1: 0x5},
+						&NormalEvent{_type: EventNoteOn, param1: 0x3c, param2: 0x5a},
+						&NormalEvent{delta: 128, tick: 128, _type: EventNoteOff, param1: 0x3c, param2: 0x40},
+					},
+				}},
+			},
+			nil,
+		},
+		{
+			"truncated file",
+			"testdata/truncated.mid",
+			nil,
+			&DecodeError{
+				Filename:    "testdata/truncated.mid",
+				Offset:      28,
+				ChunkOffset: 14,
+				Track:       0,
+				Event:       1,
+				Err:         io.ErrUnexpectedEOF,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.args)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	// the filename is opened as it is given, whatever its extension
+	dir, err := ioutil.TempDir("", "midi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data, err := ioutil.ReadFile("testdata/simple.mid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"song.MID", "song.midi"} {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(filename); err != nil {
+			t.Errorf("Load(%q) error = %v", name, err)
+		}
+	}
+	if _, err := Load("testdata/simple"); !os.IsNotExist(err) {
+		t.Errorf("Load() error = %v, want not exist", err)
+	}
+	if _, err := Load("testdata/missing"); err == nil {
+		t.Errorf("Load() of a missing file should fail")
+	}
+}
+
+func TestFile_WriteTo(t *testing.T) {
+	f, _ := NewFile(DefaultTicks,
+		NewTrack().NoteOn(0, Note("c4"), nil, 0),
+		NewTrack().Chord(2, []Pitchier{Note("c5"), Note("c3")}, 4, 0),
+	)
+	tests := []struct {
+		name    string
+		w       *limitedWriter
+		want    int64
+		wantErr bool
+	}{
+		{
+			"write file",
+			&limitedWriter{128},
+			int64(len(f.Bytes())),
+			false,
+		},
+		{
+			"short header write",
+			&limitedWriter{4},
+			4,
+			true,
+		},
+		{
+			"short track write",
+			&limitedWriter{30},
+			30,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.WriteTo(tt.w)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File.WriteTo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("File.WriteTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	w := &bytes.Buffer{}
+	f.WriteTo(w)
+	if !bytes.Equal(w.Bytes(), f.Bytes()) {
+		t.Errorf("File.WriteTo() = %v, want %v", Codes(w.Bytes()), f.Bytes())
+	}
+}
+
+func TestFile_BytesGolden(t *testing.T) {
+	format0, _ := NewFile(96,
+		NewTrack().
+			Tempo(120, nil).
+			Instrument(0, 0, nil).
+			Note(0, Note("c4"), 96, nil, 0).
+			Chord(1, []Pitchier{Note("c5"), Note("c3")}, 200, 0),
+	)
+	format1, _ := NewFile(480,
+		NewTrack().Tempo(90, nil),
+		NewTrack().
+			Instrument(9, 0x10, nil).
+			NoteOn(9, Pitch(36), TranslateTickTime(1000), 100).
+			NoteOff(9, Pitch(36), TranslateTickTime(480), 100).
+			NoteOn(9, Pitch(38), TranslateTickTime(20000), 0),
+		NewTrack(),
+	)
+	tests := []struct {
+		name   string
+		f      *File
+		golden string
+	}{
+		{
+			"format 0",
+			format0,
+			"testdata/golden/format0.mid",
+		},
+		{
+			"format 1",
+			format1,
+			"testdata/golden/format1.mid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := ioutil.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.f.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf("File.Bytes() = %v, want %v", got, Codes(want))
+			}
+			// decoding the golden file must give back the same bytes
+			f, err := Decode(bytes.NewReader(want))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got := f.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf("Decode().Bytes() = %v, want %v", got, Codes(want))
+			}
+		})
+	}
+}
+
+func TestFile_Format(t *testing.T) {
+	tests := []struct {
+		name string
+		f    *File
+		want Format
+	}{
+		{
+			"single track",
+			&File{tracks: []*Track{NewTrack()}},
+			Format0,
+		},
+		{
+			"multiple tracks",
+			&File{tracks: []*Track{NewTrack(), NewTrack()}},
+			Format1,
+		},
+		{
+			"explicit format",
+			&File{tracks: []*Track{NewTrack()}, format: Format2, formatSet: true},
+			Format2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.Format(); got != tt.want {
+				t.Errorf("File.Format() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFile_SetFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       *File
+		format  Format
+		wantErr bool
+	}{
+		{
+			"format 0",
+			&File{tracks: []*Track{NewTrack()}},
+			Format0,
+			false,
+		},
+		{
+			"format 0 with 2 tracks",
+			&File{tracks: []*Track{NewTrack(), NewTrack()}},
+			Format0,
+			true,
+		},
+		{
+			"format 2",
+			&File{tracks: []*Track{NewTrack(), NewTrack()}},
+			Format2,
+			false,
+		},
+		{
+			"unknown format",
+			&File{},
+			3,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.f.SetFormat(tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("File.SetFormat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.f.Format() != tt.format {
+				t.Errorf("File.Format() = %v, want %v", tt.f.Format(), tt.format)
+			}
+		})
+	}
+}
+
+func TestFile_EncodeFormat(t *testing.T) {
+	format2, _ := NewFile(DefaultTicks, NewTrack(), NewTrack())
+	format2.SetFormat(Format2)
+	format0, _ := NewFile(DefaultTicks)
+	format0.SetFormat(Format0)
+	if err := format0.AddTrack(nil); err != nil {
+		t.Fatalf("File.AddTrack() error = %v", err)
+	}
+	if err := format0.AddTrack(nil); err == nil {
+		t.Errorf("File.AddTrack() error = nil on a format 0 file with a track")
+	}
+	empty0, _ := NewFile(DefaultTicks)
+	empty0.SetFormat(Format0)
+	empty, _ := NewFile(DefaultTicks)
+	tests := []struct {
+		name    string
+		f       *File
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"format 2",
+			format2,
+			Codes{
+				0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
+				0x0, 0x2, 0x0, 0x2, 0x0, 0x80, 0x4d, 0x54,
+				0x72, 0x6b, 0x0, 0x0, 0x0, 0x4, 0x0, 0xff,
+				0x2f, 0x0, 0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0,
+				0x0, 0x4, 0x0, 0xff, 0x2f, 0x0,
+			},
+			false,
+		},
+		{
+			"format 0",
+			format0,
+			Codes{
+				0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
+				0x0, 0x0, 0x0, 0x1, 0x0, 0x80, 0x4d, 0x54,
+				0x72, 0x6b, 0x0, 0x0, 0x0, 0x4, 0x0, 0xff,
+				0x2f, 0x0,
+			},
+			false,
+		},
+		{
+			"format 0 without tracks",
+			empty0,
+			nil,
+			true,
+		},
+		{
+			"no tracks",
+			empty,
+			Codes{
+				0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6,
+				0x0, 0x1, 0x0, 0x0, 0x0, 0x80,
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			_, err := tt.f.Encode(w)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File.Encode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := Codes(w.Bytes()); !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("File.Encode() = %v, want %v", got, tt.want)
+			}
+			if got := tt.f.Bytes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("File.Bytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFile_SetDivision(t *testing.T) {
+	tests := []struct {
+		name     string
+		division Division
+		want     Codes
+		wantErr  bool
+	}{
+		{
+			"metrical",
+			96,
+			Codes{0x0, 0x60},
+			false,
+		},
+		{
+			"smpte",
+			0xe728,
+			Codes{0xe7, 0x28},
+			false,
+		},
+		{
+			"zero",
+			0,
+			nil,
+			true,
+		},
+		{
+			"invalid smpte",
+			0xe900,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, _ := NewFile(DefaultTicks, NewTrack())
+			if err := f.SetDivision(tt.division); (err != nil) != tt.wantErr {
+				t.Errorf("File.SetDivision() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if f.Division() != tt.division {
+				t.Errorf("File.Division() = %v, want %v", f.Division(), tt.division)
+			}
+			if got := f.Bytes()[12:14]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("File.Bytes() division = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFile_TickToSeconds(t *testing.T) {
+	metrical, _ := NewFile(96,
+		NewTrack().
+			Tempo(60, TranslateTickTime(192)).
+			Tempo(240, TranslateTickTime(96)),
+		NewTrack(),
+	)
+	smpte, _ := NewFile(DefaultTicks, NewTrack())
+	smpte.SetDivision(0xe728)
+	tests := []struct {
+		name string
+		f    *File
+		tick int
+		want float64
+	}{
+		{
+			"default tempo",
+			metrical,
+			96,
+			0.5,
+		},
+		{
+			"at tempo change",
+			metrical,
+			192,
+			1,
+		},
+		{
+			"after tempo change",
+			metrical,
+			288,
+			2,
+		},
+		{
+			"after second tempo change",
+			metrical,
+			480,
+			2.5,
+		},
+		{
+			"smpte",
+			smpte,
+			2000,
+			2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.TickToSeconds(tt.tick); got != tt.want {
+				t.Errorf("File.TickToSeconds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
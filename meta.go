@@ -0,0 +1,244 @@
+package midi
+
+import "errors"
+
+// NewTextEvent returns a new text-like meta event
+// time  - The number of ticks since the previous event, default is 0
+// _type - The meta type, from EventText to EventCuePoint
+// text  - The text of the event
+func NewTextEvent(time []byte, _type MetaType, text string) (*MetaEvent, error) {
+	return NewTextEventAfter(ParseTickTime(time), _type, text)
+}
+
+// NewTextEventAfter returns a new text-like meta event
+// delta - The number of ticks since the previous event
+// _type - The meta type, from EventText to EventCuePoint
+// text  - The text of the event
+func NewTextEventAfter(delta int, _type MetaType, text string) (*MetaEvent, error) {
+	if _type < EventText || _type > EventCuePoint {
+		return nil, errors.New("invalid text meta type")
+	}
+	return NewMetaEventAfter(delta, _type, text)
+}
+
+// NewText returns a new text meta event
+func NewText(time []byte, text string) (*MetaEvent, error) {
+	return NewTextAfter(ParseTickTime(time), text)
+}
+
+// NewTextAfter returns a new text meta event
+func NewTextAfter(delta int, text string) (*MetaEvent, error) {
+	return NewTextEventAfter(delta, EventText, text)
+}
+
+// NewCopyright returns a new copyright notice meta event
+func NewCopyright(time []byte, text string) (*MetaEvent, error) {
+	return NewCopyrightAfter(ParseTickTime(time), text)
+}
+
+// NewCopyrightAfter returns a new copyright notice meta event
+func NewCopyrightAfter(delta int, text string) (*MetaEvent, error) {
+	return NewTextEventAfter(delta, EventCopyright, text)
+}
+
+// NewTrackName returns a new sequence/track name meta event
+func NewTrackName(time []byte, text string) (*MetaEvent, error) {
+	return NewTrackNameAfter(ParseTickTime(time), text)
+}
+
+// NewTrackNameAfter returns a new sequence/track name meta event
+func NewTrackNameAfter(delta int, text string) (*MetaEvent, error) {
+	return NewTextEventAfter(delta, EventTrackName, text)
+}
+
+// NewInstrumentName returns a new instrument name meta event
+func NewInstrumentName(time []byte, text string) (*MetaEvent, error) {
+	return NewInstrumentNameAfter(ParseTickTime(time), text)
+}
+
+// NewInstrumentNameAfter returns a new instrument name meta event
+func NewInstrumentNameAfter(delta int, text string) (*MetaEvent, error) {
+	return NewTextEventAfter(delta, EventInstrument, text)
+}
+
+// NewLyric returns a new lyric meta event
+func NewLyric(time []byte, text string) (*MetaEvent, error) {
+	return NewLyricAfter(ParseTickTime(time), text)
+}
+
+// NewLyricAfter returns a new lyric meta event
+func NewLyricAfter(delta int, text string) (*MetaEvent, error) {
+	return NewTextEventAfter(delta, EventLyric, text)
+}
+
+// NewMarker returns a new marker meta event
+func NewMarker(time []byte, text string) (*MetaEvent, error) {
+	return NewMarkerAfter(ParseTickTime(time), text)
+}
+
+// NewMarkerAfter returns a new marker meta event
+func NewMarkerAfter(delta int, text string) (*MetaEvent, error) {
+	return NewTextEventAfter(delta, EventMarker, text)
+}
+
+// NewCuePoint returns a new cue point meta event
+func NewCuePoint(time []byte, text string) (*MetaEvent, error) {
+	return NewCuePointAfter(ParseTickTime(time), text)
+}
+
+// NewCuePointAfter returns a new cue point meta event
+func NewCuePointAfter(delta int, text string) (*MetaEvent, error) {
+	return NewTextEventAfter(delta, EventCuePoint, text)
+}
+
+// NewSequenceNumber returns a new sequence number meta event
+// time - The number of ticks since the previous event, default is 0
+// n    - The number of the sequence
+func NewSequenceNumber(time []byte, n uint16) (*MetaEvent, error) {
+	return NewSequenceNumberAfter(ParseTickTime(time), n)
+}
+
+// NewSequenceNumberAfter returns a new sequence number meta event
+// delta - The number of ticks since the previous event
+// n     - The number of the sequence
+func NewSequenceNumberAfter(delta int, n uint16) (*MetaEvent, error) {
+	return NewMetaEventAfter(delta, EventSequence, []byte{byte(n >> 8), byte(n)})
+}
+
+// NewChannelPrefix returns a new channel prefix meta event
+// time    - The number of ticks since the previous event, default is 0
+// channel - The channel the following meta and sysex events refer to
+func NewChannelPrefix(time []byte, channel int) (*MetaEvent, error) {
+	return NewChannelPrefixAfter(ParseTickTime(time), channel)
+}
+
+// NewChannelPrefixAfter returns a new channel prefix meta event
+// delta   - The number of ticks since the previous event
+// channel - The channel the following meta and sysex events refer to
+func NewChannelPrefixAfter(delta, channel int) (*MetaEvent, error) {
+	if channel < 0 || channel > 15 {
+		return nil, errors.New("channel out of bounds")
+	}
+	return NewMetaEventAfter(delta, EventChannelPrefix, []byte{byte(channel)})
+}
+
+// NewPortPrefix returns a new port prefix meta event
+// time - The number of ticks since the previous event, default is 0
+// port - The midi port the events of the track are sent to
+func NewPortPrefix(time []byte, port int) (*MetaEvent, error) {
+	return NewPortPrefixAfter(ParseTickTime(time), port)
+}
+
+// NewPortPrefixAfter returns a new port prefix meta event
+// delta - The number of ticks since the previous event
+// port  - The midi port the events of the track are sent to
+func NewPortPrefixAfter(delta, port int) (*MetaEvent, error) {
+	if port < 0 || port > 127 {
+		return nil, errors.New("port out of bounds")
+	}
+	return NewMetaEventAfter(delta, EventPortPrefix, []byte{byte(port)})
+}
+
+// NewSMPTEOffset returns a new SMPTE offset meta event
+// time      - The number of ticks since the previous event, default is 0
+// fps       - The SMPTE frame rate, one of SMPTE24, SMPTE25, SMPTE30Drop or SMPTE30
+// hours     - The hours, between 0 and 23
+// minutes   - The minutes, between 0 and 59
+// seconds   - The seconds, between 0 and 59
+// frames    - The frames, between 0 and 23, 24 or 29
+// subframes - The fractional frames in 100ths of a frame, between 0 and 99
+func NewSMPTEOffset(time []byte, fps, hours, minutes, seconds, frames, subframes int) (*MetaEvent, error) {
+	return NewSMPTEOffsetAfter(ParseTickTime(time), fps, hours, minutes, seconds, frames, subframes)
+}
+
+// NewSMPTEOffsetAfter returns a new SMPTE offset meta event
+// delta     - The number of ticks since the previous event
+// fps       - The SMPTE frame rate, one of SMPTE24, SMPTE25, SMPTE30Drop or SMPTE30
+// hours     - The hours, between 0 and 23
+// minutes   - The minutes, between 0 and 59
+// seconds   - The seconds, between 0 and 59
+// frames    - The frames, between 0 and 23, 24 or 29
+// subframes - The fractional frames in 100ths of a frame, between 0 and 99
+func NewSMPTEOffsetAfter(delta int, fps, hours, minutes, seconds, frames, subframes int) (*MetaEvent, error) {
+	// drop frame timecode still numbers 30 frames per second
+	var rate byte
+	maxFrames := fps
+	switch fps {
+	case SMPTE24:
+		rate = 0
+	case SMPTE25:
+		rate = 1
+	case SMPTE30Drop:
+		rate = 2
+		maxFrames = 30
+	case SMPTE30:
+		rate = 3
+	default:
+		return nil, errors.New("invalid smpte frame rate")
+	}
+	if hours < 0 || hours > 23 || minutes < 0 || minutes > 59 || seconds < 0 || seconds > 59 {
+		return nil, errors.New("invalid smpte time")
+	}
+	if frames < 0 || frames >= maxFrames || subframes < 0 || subframes > 99 {
+		return nil, errors.New("invalid smpte frames")
+	}
+	return NewMetaEventAfter(delta, EventSmpte, []byte{
+		rate<<5 | byte(hours),
+		byte(minutes),
+		byte(seconds),
+		byte(frames),
+		byte(subframes),
+	})
+}
+
+// NewTimeSignature returns a new time signature meta event
+// time           - The number of ticks since the previous event, default is 0
+// num            - The numerator of the time signature
+// denomPow2      - The denominator as a power of two, 2 for a quarter note
+// clocksPerClick - The midi clocks per metronome click, 24 for a quarter note
+// n32PerQuarter  - The notated 32nd notes per midi quarter note, usually 8
+func NewTimeSignature(time []byte, num, denomPow2, clocksPerClick, n32PerQuarter byte) (*MetaEvent, error) {
+	return NewTimeSignatureAfter(ParseTickTime(time), num, denomPow2, clocksPerClick, n32PerQuarter)
+}
+
+// NewTimeSignatureAfter returns a new time signature meta event
+// delta          - The number of ticks since the previous event
+// num            - The numerator of the time signature
+// denomPow2      - The denominator as a power of two, 2 for a quarter note
+// clocksPerClick - The midi clocks per metronome click, 24 for a quarter note
+// n32PerQuarter  - The notated 32nd notes per midi quarter note, usually 8
+func NewTimeSignatureAfter(delta int, num, denomPow2, clocksPerClick, n32PerQuarter byte) (*MetaEvent, error) {
+	if num == 0 {
+		return nil, errors.New("invalid time signature numerator")
+	}
+	if denomPow2 > 7 {
+		return nil, errors.New("invalid time signature denominator")
+	}
+	if clocksPerClick == 0 || n32PerQuarter == 0 {
+		return nil, errors.New("invalid time signature clocks")
+	}
+	return NewMetaEventAfter(delta, EventTimeSig, []byte{num, denomPow2, clocksPerClick, n32PerQuarter})
+}
+
+// NewKeySignature returns a new key signature meta event
+// time        - The number of ticks since the previous event, default is 0
+// sharpsFlats - The number of sharps (positive) or flats (negative), from -7 to 7
+// minor       - Whether the key is minor
+func NewKeySignature(time []byte, sharpsFlats int8, minor bool) (*MetaEvent, error) {
+	return NewKeySignatureAfter(ParseTickTime(time), sharpsFlats, minor)
+}
+
+// NewKeySignatureAfter returns a new key signature meta event
+// delta       - The number of ticks since the previous event
+// sharpsFlats - The number of sharps (positive) or flats (negative), from -7 to 7
+// minor       - Whether the key is minor
+func NewKeySignatureAfter(delta int, sharpsFlats int8, minor bool) (*MetaEvent, error) {
+	if sharpsFlats < -7 || sharpsFlats > 7 {
+		return nil, errors.New("invalid key signature")
+	}
+	mi := byte(0)
+	if minor {
+		mi = 1
+	}
+	return NewMetaEventAfter(delta, EventKeySig, []byte{byte(sharpsFlats), mi})
+}
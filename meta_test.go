@@ -0,0 +1,306 @@
+package midi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewTextEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		new     func(time []byte, text string) (*MetaEvent, error)
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"text",
+			NewText,
+			Codes{0x0, 0xff, 0x1, 0x2, 0x68, 0x69},
+			false,
+		},
+		{
+			"copyright",
+			NewCopyright,
+			Codes{0x0, 0xff, 0x2, 0x2, 0x68, 0x69},
+			false,
+		},
+		{
+			"track name",
+			NewTrackName,
+			Codes{0x0, 0xff, 0x3, 0x2, 0x68, 0x69},
+			false,
+		},
+		{
+			"instrument name",
+			NewInstrumentName,
+			Codes{0x0, 0xff, 0x4, 0x2, 0x68, 0x69},
+			false,
+		},
+		{
+			"lyric",
+			NewLyric,
+			Codes{0x0, 0xff, 0x5, 0x2, 0x68, 0x69},
+			false,
+		},
+		{
+			"marker",
+			NewMarker,
+			Codes{0x0, 0xff, 0x6, 0x2, 0x68, 0x69},
+			false,
+		},
+		{
+			"cue point",
+			NewCuePoint,
+			Codes{0x0, 0xff, 0x7, 0x2, 0x68, 0x69},
+			false,
+		},
+		{
+			"invalid text type",
+			func(time []byte, text string) (*MetaEvent, error) {
+				return NewTextEvent(time, EventTempo, text)
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.new(nil, "hi")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTextEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewTextEvent() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSequenceNumber(t *testing.T) {
+	got, err := NewSequenceNumber(nil, 0x102)
+	if err != nil {
+		t.Fatalf("NewSequenceNumber() error = %v", err)
+	}
+	if want := (Codes{0x0, 0xff, 0x0, 0x2, 0x1, 0x2}); !reflect.DeepEqual(got.Bytes(), want) {
+		t.Errorf("NewSequenceNumber() = %v, want %v", got.Bytes(), want)
+	}
+}
+
+func TestNewChannelPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel int
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"channel prefix",
+			9,
+			Codes{0x0, 0xff, 0x20, 0x1, 0x9},
+			false,
+		},
+		{
+			"invalid channel",
+			16,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewChannelPrefix(nil, tt.channel)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewChannelPrefix() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewChannelPrefix() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPortPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		port    int
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"port prefix",
+			1,
+			Codes{0x0, 0xff, 0x21, 0x1, 0x1},
+			false,
+		},
+		{
+			"invalid port",
+			128,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPortPrefix(nil, tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPortPrefix() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewPortPrefix() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSMPTEOffset(t *testing.T) {
+	type args struct {
+		fps, hours, minutes, seconds, frames, subframes int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"25 fps",
+			args{SMPTE25, 1, 2, 3, 24, 99},
+			Codes{0x0, 0xff, 0x54, 0x5, 0x21, 0x2, 0x3, 0x18, 0x63},
+			false,
+		},
+		{
+			"30 drop fps",
+			args{SMPTE30Drop, 23, 59, 59, 29, 0},
+			Codes{0x0, 0xff, 0x54, 0x5, 0x57, 0x3b, 0x3b, 0x1d, 0x0},
+			false,
+		},
+		{
+			"invalid frame rate",
+			args{48, 0, 0, 0, 0, 0},
+			nil,
+			true,
+		},
+		{
+			"invalid time",
+			args{SMPTE24, 24, 0, 0, 0, 0},
+			nil,
+			true,
+		},
+		{
+			"invalid frames",
+			args{SMPTE24, 0, 0, 0, 24, 0},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.args
+			got, err := NewSMPTEOffset(nil, a.fps, a.hours, a.minutes, a.seconds, a.frames, a.subframes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSMPTEOffset() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewSMPTEOffset() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTimeSignature(t *testing.T) {
+	type args struct {
+		num, denomPow2, clocksPerClick, n32PerQuarter byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"6/8",
+			args{6, 3, 36, 8},
+			Codes{0x0, 0xff, 0x58, 0x4, 0x6, 0x3, 0x24, 0x8},
+			false,
+		},
+		{
+			"zero numerator",
+			args{0, 2, 24, 8},
+			nil,
+			true,
+		},
+		{
+			"invalid denominator",
+			args{4, 8, 24, 8},
+			nil,
+			true,
+		},
+		{
+			"zero clocks",
+			args{4, 2, 0, 8},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.args
+			got, err := NewTimeSignature(nil, a.num, a.denomPow2, a.clocksPerClick, a.n32PerQuarter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTimeSignature() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewTimeSignature() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKeySignature(t *testing.T) {
+	type args struct {
+		sharpsFlats int8
+		minor       bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"d major",
+			args{2, false},
+			Codes{0x0, 0xff, 0x59, 0x2, 0x2, 0x0},
+			false,
+		},
+		{
+			"c minor",
+			args{-3, true},
+			Codes{0x0, 0xff, 0x59, 0x2, 0xfd, 0x1},
+			false,
+		},
+		{
+			"too many sharps",
+			args{8, false},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewKeySignature(nil, tt.args.sharpsFlats, tt.args.minor)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKeySignature() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewKeySignature() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
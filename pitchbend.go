@@ -0,0 +1,148 @@
+package midi
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	// MinPitchBend is the lowest pitch bend value
+	MinPitchBend = -8192
+	// MaxPitchBend is the highest pitch bend value
+	MaxPitchBend = 8191
+	// DefaultPitchBendRange is the pitch bend range, in semitones,
+	// of a channel whose pitch bend sensitivity wasn't changed
+	DefaultPitchBendRange = 2
+)
+
+// NewPitchBend returns a new pitch bend event
+// time    - The number of ticks since the previous event, default is 0
+// channel - The channel of the event
+// value   - The bend, from MinPitchBend to MaxPitchBend, 0 is no bend
+func NewPitchBend(time []byte, channel int, value int16) (*NormalEvent, error) {
+	return NewPitchBendAfter(ParseTickTime(time), channel, value)
+}
+
+// NewPitchBendAfter returns a new pitch bend event
+// delta   - The number of ticks since the previous event
+// channel - The channel of the event
+// value   - The bend, from MinPitchBend to MaxPitchBend, 0 is no bend
+func NewPitchBendAfter(delta, channel int, value int16) (*NormalEvent, error) {
+	if value < MinPitchBend || value > MaxPitchBend {
+		return nil, errors.New("pitch bend out of bounds")
+	}
+	// the 14-bit value is centered at 0x2000 and sent LSB first
+	v := int(value) - MinPitchBend
+	return NewEventAfter(delta, EventPitchBend, channel, byte(v&0x7F), byte(v>>7))
+}
+
+// AddPitchBend adds a pitch bend event to the track
+// channel - The channel to add the event to
+// value   - The bend, from MinPitchBend to MaxPitchBend, 0 is no bend
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) AddPitchBend(channel int, value int16, time []byte) *Track {
+	return t.PitchBendAfter(channel, value, ParseTickTime(time))
+}
+
+// PitchBend adds a pitch bend event to the track
+// channel - The channel to add the event to
+// value   - The bend, from MinPitchBend to MaxPitchBend, 0 is no bend
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) PitchBend(channel int, value int16, time []byte) *Track {
+	return t.AddPitchBend(channel, value, time)
+}
+
+// PitchBendAfter adds a pitch bend event to the track
+// channel - The channel to add the event to
+// value   - The bend, from MinPitchBend to MaxPitchBend, 0 is no bend
+// delta   - The number of ticks since the previous event
+func (t *Track) PitchBendAfter(channel int, value int16, delta int) *Track {
+	return t.add(NewPitchBendAfter(delta, channel, value))
+}
+
+// PitchBendSemitones adds a pitch bend event to the track
+// channel   - The channel to add the event to
+// semitones - The bend in semitones, between -bendRange and bendRange
+// bendRange - The pitch bend range of the channel, see DefaultPitchBendRange
+// time      - The number of ticks since the previous event, default is 0
+func (t *Track) PitchBendSemitones(channel int, semitones, bendRange float64, time []byte) *Track {
+	return t.PitchBendSemitonesAfter(channel, semitones, bendRange, ParseTickTime(time))
+}
+
+// PitchBendSemitonesAfter adds a pitch bend event to the track
+// channel   - The channel to add the event to
+// semitones - The bend in semitones, between -bendRange and bendRange
+// bendRange - The pitch bend range of the channel, see DefaultPitchBendRange
+// delta     - The number of ticks since the previous event
+func (t *Track) PitchBendSemitonesAfter(channel int, semitones, bendRange float64, delta int) *Track {
+	value, err := semitonesToBend(semitones, bendRange)
+	if err != nil {
+		return t.fail(err)
+	}
+	return t.PitchBendAfter(channel, value, delta)
+}
+
+// PitchBendRamp adds pitch bend events to the track gliding from one
+// bend to another, the last event always has the final bend
+// channel - The channel to add the events to
+// from    - The bend at the start of the ramp
+// to      - The bend at the end of the ramp
+// dur     - The duration of the ramp, in ticks
+// step    - The ticks between each event of the ramp
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) PitchBendRamp(channel int, from, to int16, dur, step int, time []byte) *Track {
+	return t.PitchBendRampAfter(channel, from, to, dur, step, ParseTickTime(time))
+}
+
+// PitchBendRampAfter adds pitch bend events to the track gliding from
+// one bend to another, the last event always has the final bend
+// channel - The channel to add the events to
+// from    - The bend at the start of the ramp
+// to      - The bend at the end of the ramp
+// dur     - The duration of the ramp, in ticks
+// step    - The ticks between each event of the ramp
+// delta   - The number of ticks since the previous event
+func (t *Track) PitchBendRampAfter(channel int, from, to int16, dur, step, delta int) *Track {
+	return t.ramp(float64(from), float64(to), dur, step, delta, func(v float64, delta int) *Track {
+		return t.PitchBendAfter(channel, int16(v), delta)
+	})
+}
+
+// ramp calls add with values linearly interpolated between from and to,
+// every step ticks over dur ticks, the first value is added delta
+// ticks after the previous event
+func (t *Track) ramp(from, to float64, dur, step, delta int, add func(v float64, delta int) *Track) *Track {
+	if dur < 0 || step <= 0 {
+		return t.fail(errors.New("invalid ramp duration or step"))
+	}
+	if dur == 0 {
+		from = to
+	}
+	if add(from, delta).err != nil {
+		return t
+	}
+	for tick := step; tick-step < dur; tick += step {
+		delta := step
+		if tick > dur {
+			delta -= tick - dur
+			tick = dur
+		}
+		v := from + (to-from)*float64(tick)/float64(dur)
+		if add(math.Floor(v+0.5), delta).err != nil {
+			return t
+		}
+	}
+	return t
+}
+
+// semitonesToBend converts a bend in semitones to a pitch bend value
+func semitonesToBend(semitones, bendRange float64) (int16, error) {
+	if bendRange <= 0 || math.Abs(semitones) > bendRange {
+		return 0, errors.New("pitch bend out of range")
+	}
+	v := math.Floor(semitones/bendRange*-MinPitchBend + 0.5)
+	if v > MaxPitchBend {
+		v = MaxPitchBend
+	}
+	return int16(v), nil
+}
@@ -0,0 +1,172 @@
+package midi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPitchBend(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   int16
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"center",
+			0,
+			Codes{0x0, 0xe2, 0x0, 0x40},
+			false,
+		},
+		{
+			"lowest",
+			MinPitchBend,
+			Codes{0x0, 0xe2, 0x0, 0x0},
+			false,
+		},
+		{
+			"highest",
+			MaxPitchBend,
+			Codes{0x0, 0xe2, 0x7f, 0x7f},
+			false,
+		},
+		{
+			"lsb and msb",
+			-8191 + 0x1234,
+			Codes{0x0, 0xe2, 0x35, 0x24},
+			false,
+		},
+		{
+			"too low",
+			MinPitchBend - 1,
+			nil,
+			true,
+		},
+		{
+			"too high",
+			MaxPitchBend + 1,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPitchBend(nil, 2, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPitchBend() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("NewPitchBend() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTrack_PitchBendSemitones(t *testing.T) {
+	type args struct {
+		semitones float64
+		bendRange float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Track
+	}{
+		{
+			"one semitone up",
+			args{1, DefaultPitchBendRange},
+			NewTrack().PitchBend(0, 4096, nil),
+		},
+		{
+			"full range up",
+			args{2, DefaultPitchBendRange},
+			NewTrack().PitchBend(0, MaxPitchBend, nil),
+		},
+		{
+			"full range down",
+			args{-12, 12},
+			NewTrack().PitchBend(0, MinPitchBend, nil),
+		},
+		{
+			"out of range",
+			args{3, DefaultPitchBendRange},
+			nil,
+		},
+		{
+			"invalid range",
+			args{0, 0},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewTrack().PitchBendSemitones(0, tt.args.semitones, tt.args.bendRange, nil)
+			if tt.want == nil {
+				if got.Err() == nil {
+					t.Errorf("Track.PitchBendSemitones() error = nil, want error")
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Track.PitchBendSemitones() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrack_PitchBendRamp(t *testing.T) {
+	type args struct {
+		from, to  int16
+		dur, step int
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Track
+	}{
+		{
+			"ramp",
+			args{0, 1000, 100, 30},
+			NewTrack().
+				PitchBend(0, 0, TranslateTickTime(10)).
+				PitchBend(0, 300, TranslateTickTime(30)).
+				PitchBend(0, 600, TranslateTickTime(30)).
+				PitchBend(0, 900, TranslateTickTime(30)).
+				PitchBend(0, 1000, TranslateTickTime(10)),
+		},
+		{
+			"ramp down",
+			args{0, -100, 20, 10},
+			NewTrack().
+				PitchBend(0, 0, TranslateTickTime(10)).
+				PitchBend(0, -50, TranslateTickTime(10)).
+				PitchBend(0, -100, TranslateTickTime(10)),
+		},
+		{
+			"no duration",
+			args{0, 100, 0, 10},
+			NewTrack().PitchBend(0, 100, TranslateTickTime(10)),
+		},
+		{
+			"invalid step",
+			args{0, 100, 10, 0},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.args
+			got := NewTrack().PitchBendRamp(0, a.from, a.to, a.dur, a.step, TranslateTickTime(10))
+			if tt.want == nil {
+				if got.Err() == nil {
+					t.Errorf("Track.PitchBendRamp() error = nil, want error")
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Track.PitchBendRamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
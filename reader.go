@@ -0,0 +1,82 @@
+package midi
+
+import "io"
+
+// Reader reads the events of a Standard MIDI File one at a time,
+// without loading its tracks in memory
+type Reader struct {
+	d        *decoder
+	header   bool
+	format   Format
+	ntrks    int
+	division Division
+	// absolute time of the last event of the current track
+	tick int
+	// whether the current track has no events left
+	done bool
+	// absolute times of the end of track events of the finished
+	// tracks, 0 for the ones right after the last event
+	ends []int
+	err  error
+}
+
+// NewReader returns a new Reader reading from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		d:    newDecoder(r),
+		done: true,
+	}
+}
+
+// Header reads the file header if it wasn't read yet, returning the
+// format, the number of tracks and the time division of the file
+func (r *Reader) Header() (format Format, tracks int, division Division, err error) {
+	if !r.header && r.err == nil {
+		r.format, r.ntrks, r.division, r.err = r.d.readHeader()
+		if r.err != nil {
+			r.err = r.d.wrap(r.err)
+		}
+		r.header = true
+	}
+	return r.format, r.ntrks, r.division, r.err
+}
+
+// Next returns the next event of the file, along with the index of
+// its track and its time in ticks since the start of the track.
+// It returns io.EOF once every track was read, errors about
+// malformed input are returned as *DecodeError
+func (r *Reader) Next() (track, tick int, e Event, err error) {
+	if _, _, _, err := r.Header(); err != nil {
+		return 0, 0, nil, err
+	}
+	for {
+		if r.done {
+			if r.d.track+1 >= r.ntrks {
+				return 0, 0, nil, io.EOF
+			}
+			if err := r.d.nextTrack(); err != nil {
+				r.err = r.d.wrap(err)
+				return 0, 0, nil, r.err
+			}
+			r.tick = 0
+			r.done = false
+		}
+		delta, e, err := r.d.readEvent()
+		if err != nil {
+			r.err = r.d.wrap(err)
+			return 0, 0, nil, r.err
+		}
+		if e == nil {
+			r.done = true
+			end := 0
+			if r.d.eotDelta != 0 {
+				end = r.tick + r.d.eotDelta
+			}
+			r.ends = append(r.ends, end)
+			continue
+		}
+		r.tick += delta
+		setTick(e, r.tick)
+		return r.d.track, r.tick, e, nil
+	}
+}
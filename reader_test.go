@@ -0,0 +1,122 @@
+package midi
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReader_Header(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantFormat   Format
+		wantTracks   int
+		wantDivision Division
+		wantErr      bool
+	}{
+		{
+			"header",
+			[]byte{0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x3, 0x1, 0xe0},
+			Format1,
+			3,
+			480,
+			false,
+		},
+		{
+			"invalid header",
+			[]byte{0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x2, 0x0, 0x1},
+			0,
+			0,
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFormat, gotTracks, gotDivision, err := NewReader(bytes.NewReader(tt.data)).Header()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Reader.Header() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotFormat != tt.wantFormat || gotTracks != tt.wantTracks || gotDivision != tt.wantDivision {
+				t.Errorf("Reader.Header() = %v, %v, %v, want %v, %v, %v", gotFormat, gotTracks, gotDivision, tt.wantFormat, tt.wantTracks, tt.wantDivision)
+			}
+		})
+	}
+}
+
+func TestReader_Next(t *testing.T) {
+	type event struct {
+		track, tick int
+		e           Event
+	}
+	ff, err := os.Open("testdata/simple.mid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ff.Close()
+	multi := []byte{
+		0x4d, 0x54, 0x68, 0x64, 0x0, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x2, 0x0, 0x80,
+		0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x9, 0x83, 0x0, 0xff, 0x1, 0x0, 0x0, 0xff, 0x2f, 0x0,
+		0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x3, 0x1, 0xc0, 0x1,
+	}
+	tests := []struct {
+		name    string
+		r       io.Reader
+		want    []event
+		wantErr bool
+	}{
+		{
+			"single track",
+			ff,
+			[]event{
+				{0, 0, &NormalEvent{_type: EventProgramChange, param1: 0x5}},
+				{0, 0, &NormalEvent{_type: EventNoteOn, param1: 0x3c, param2: 0x5a}},
+				{0, 128, &NormalEvent{delta: 128, tick: 128, _type: EventNoteOff, param1: 0x3c, param2: 0x40}},
+			},
+			false,
+		},
+		{
+			"multiple tracks",
+			bytes.NewReader(multi),
+			[]event{
+				{0, 384, &MetaEvent{delta: 384, tick: 384, _type: 0x1, data: []byte{}}},
+				{1, 1, &NormalEvent{delta: 1, tick: 1, _type: EventProgramChange, param1: 0x1}},
+			},
+			false,
+		},
+		{
+			"truncated track",
+			bytes.NewReader(multi[:len(multi)-2]),
+			[]event{
+				{0, 384, &MetaEvent{delta: 384, tick: 384, _type: 0x1, data: []byte{}}},
+			},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(tt.r)
+			var got []event
+			var err error
+			for {
+				var ev event
+				ev.track, ev.tick, ev.e, err = r.Next()
+				if err != nil {
+					break
+				}
+				got = append(got, ev)
+			}
+			if (err != io.EOF) != tt.wantErr {
+				t.Errorf("Reader.Next() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reader.Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
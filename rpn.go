@@ -0,0 +1,224 @@
+package midi
+
+import "errors"
+
+// Parameter is the 14-bit number of a registered (RPN) or
+// non-registered (NRPN) parameter, MSB first
+type Parameter uint16
+
+const (
+	// RPNPitchBendSensitivity is the pitch bend range, semitones in
+	// the MSB of the value and cents in the LSB
+	RPNPitchBendSensitivity Parameter = 0x0000
+	// RPNFineTuning is the channel fine tuning, 0x2000 is A440
+	RPNFineTuning Parameter = 0x0001
+	// RPNCoarseTuning is the channel coarse tuning, semitones in the MSB
+	// of the value, 0x40 is A440
+	RPNCoarseTuning Parameter = 0x0002
+	// RPNTuningProgram selects a tuning program, in the MSB of the value
+	RPNTuningProgram Parameter = 0x0003
+	// RPNTuningBank selects a tuning bank, in the MSB of the value
+	RPNTuningBank Parameter = 0x0004
+	// RPNModulationDepthRange is the modulation depth range
+	RPNModulationDepthRange Parameter = 0x0005
+	// RPNMPEConfiguration is the MPE configuration message, the number
+	// of member channels in the MSB of the value
+	RPNMPEConfiguration Parameter = 0x0006
+	// RPNNull deselects the current parameter
+	RPNNull Parameter = 0x3FFF
+)
+
+// MaxParameterValue is the highest value of a parameter
+const MaxParameterValue = 0x3FFF
+
+// ParameterChange is a logical change of a registered
+// or non-registered parameter
+type ParameterChange struct {
+	// Tick is the absolute time of the change in ticks
+	Tick int
+	// Channel is the channel of the change
+	Channel int
+	// Registered is true for RPN and false for NRPN changes
+	Registered bool
+	// Param is the changed parameter
+	Param Parameter
+	// Value is the new 14-bit value of the parameter
+	Value uint16
+}
+
+// AddRPN adds the controller events setting a registered parameter to
+// the track, followed by the null RPN so later data entry events
+// don't change it by mistake
+// channel - The channel to add the events to
+// param   - The parameter to set
+// value   - The 14-bit value of the parameter
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) AddRPN(channel int, param Parameter, value uint16, time []byte) *Track {
+	return t.SetRPNAfter(channel, param, value, ParseTickTime(time))
+}
+
+// SetRPN adds the controller events setting a registered parameter to
+// the track, followed by the null RPN so later data entry events
+// don't change it by mistake
+// channel - The channel to add the events to
+// param   - The parameter to set
+// value   - The 14-bit value of the parameter
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) SetRPN(channel int, param Parameter, value uint16, time []byte) *Track {
+	return t.AddRPN(channel, param, value, time)
+}
+
+// SetRPNAfter adds the controller events setting a registered parameter to
+// the track, followed by the null RPN so later data entry events
+// don't change it by mistake
+// channel - The channel to add the events to
+// param   - The parameter to set
+// value   - The 14-bit value of the parameter
+// delta   - The number of ticks since the previous event
+func (t *Track) SetRPNAfter(channel int, param Parameter, value uint16, delta int) *Track {
+	return t.addParameter(channel, ControllerRPNMSB, ControllerRPNLSB, param, value, delta)
+}
+
+// AddNRPN adds the controller events setting a non-registered parameter
+// to the track, followed by the null RPN so later data entry events
+// don't change it by mistake
+// channel - The channel to add the events to
+// param   - The parameter to set
+// value   - The 14-bit value of the parameter
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) AddNRPN(channel int, param Parameter, value uint16, time []byte) *Track {
+	return t.SetNRPNAfter(channel, param, value, ParseTickTime(time))
+}
+
+// SetNRPN adds the controller events setting a non-registered parameter
+// to the track, followed by the null RPN so later data entry events
+// don't change it by mistake
+// channel - The channel to add the events to
+// param   - The parameter to set
+// value   - The 14-bit value of the parameter
+// time    - The number of ticks since the previous event, default is 0
+func (t *Track) SetNRPN(channel int, param Parameter, value uint16, time []byte) *Track {
+	return t.AddNRPN(channel, param, value, time)
+}
+
+// SetNRPNAfter adds the controller events setting a non-registered parameter
+// to the track, followed by the null RPN so later data entry events
+// don't change it by mistake
+// channel - The channel to add the events to
+// param   - The parameter to set
+// value   - The 14-bit value of the parameter
+// delta   - The number of ticks since the previous event
+func (t *Track) SetNRPNAfter(channel int, param Parameter, value uint16, delta int) *Track {
+	return t.addParameter(channel, ControllerNRPNMSB, ControllerNRPNLSB, param, value, delta)
+}
+
+// PitchBendRange sets the pitch bend sensitivity of a channel
+// channel   - The channel to add the events to
+// semitones - The semitones of the range, see DefaultPitchBendRange
+// cents     - The cents added to the range
+// time      - The number of ticks since the previous event, default is 0
+func (t *Track) PitchBendRange(channel int, semitones, cents byte, time []byte) *Track {
+	return t.PitchBendRangeAfter(channel, semitones, cents, ParseTickTime(time))
+}
+
+// PitchBendRangeAfter sets the pitch bend sensitivity of a channel
+// channel   - The channel to add the events to
+// semitones - The semitones of the range, see DefaultPitchBendRange
+// cents     - The cents added to the range
+// delta     - The number of ticks since the previous event
+func (t *Track) PitchBendRangeAfter(channel int, semitones, cents byte, delta int) *Track {
+	if semitones > 0x7F || cents > 0x7F {
+		return t.fail(errors.New("pitch bend range out of bounds"))
+	}
+	return t.SetRPNAfter(channel, RPNPitchBendSensitivity, uint16(semitones)<<7|uint16(cents), delta)
+}
+
+// addParameter adds the controller events setting a parameter
+func (t *Track) addParameter(channel int, msb, lsb Controller, param Parameter, value uint16, delta int) *Track {
+	if param > RPNNull {
+		return t.fail(errors.New("parameter out of bounds"))
+	}
+	if value > MaxParameterValue {
+		return t.fail(errors.New("parameter value out of bounds"))
+	}
+	events := []struct {
+		controller Controller
+		value      byte
+	}{
+		{msb, byte(param >> 7)},
+		{lsb, byte(param & 0x7F)},
+		{ControllerDataEntryMSB, byte(value >> 7)},
+		{ControllerDataEntryLSB, byte(value & 0x7F)},
+		// the null RPN deselects both registered and non-registered parameters
+		{ControllerRPNMSB, byte(RPNNull >> 7)},
+		{ControllerRPNLSB, byte(RPNNull & 0x7F)},
+	}
+	for i, e := range events {
+		if i > 0 {
+			delta = 0
+		}
+		if t.ControlChangeAfter(channel, e.controller, e.value, delta).err != nil {
+			return t
+		}
+	}
+	return t
+}
+
+// ParameterChanges reassembles the controller events of the track
+// setting registered and non-registered parameters into logical
+// changes, in the order they happen
+func (t *Track) ParameterChanges() []ParameterChange {
+	type state struct {
+		registered bool
+		msb, lsb   byte
+		// index of the last change, while it can still get its LSB
+		last int
+	}
+	var channels [16]state
+	for i := range channels {
+		channels[i] = state{msb: 0x7F, lsb: 0x7F, last: -1}
+	}
+	changes := []ParameterChange{}
+	for _, te := range t.timeline() {
+		e, ok := te.e.(*NormalEvent)
+		if !ok || e._type != EventController {
+			continue
+		}
+		s := &channels[e.channel]
+		switch Controller(e.param1) {
+		case ControllerRPNMSB, ControllerNRPNMSB:
+			s.registered = Controller(e.param1) == ControllerRPNMSB
+			s.msb = e.param2
+			s.last = -1
+		case ControllerRPNLSB, ControllerNRPNLSB:
+			s.registered = Controller(e.param1) == ControllerRPNLSB
+			s.lsb = e.param2
+			s.last = -1
+		case ControllerDataEntryMSB, ControllerDataEntryLSB:
+			param := Parameter(s.msb)<<7 | Parameter(s.lsb)
+			if param == RPNNull {
+				continue
+			}
+			if Controller(e.param1) == ControllerDataEntryLSB && s.last >= 0 {
+				c := &changes[s.last]
+				c.Value = c.Value&^0x7F | uint16(e.param2)
+				s.last = -1
+				continue
+			}
+			c := ParameterChange{
+				Tick:       te.tick,
+				Channel:    e.channel,
+				Registered: s.registered,
+				Param:      param,
+			}
+			if Controller(e.param1) == ControllerDataEntryMSB {
+				c.Value = uint16(e.param2) << 7
+				s.last = len(changes)
+			} else {
+				c.Value = uint16(e.param2)
+			}
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,139 @@
+package midi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrack_SetRPN(t *testing.T) {
+	tests := []struct {
+		name string
+		got  *Track
+		want *Track
+	}{
+		{
+			"pitch bend sensitivity",
+			NewTrack().SetRPN(1, RPNPitchBendSensitivity, 12<<7, TranslateTickTime(10)),
+			NewTrack().
+				ControlChange(1, ControllerRPNMSB, 0, TranslateTickTime(10)).
+				ControlChange(1, ControllerRPNLSB, 0, nil).
+				ControlChange(1, ControllerDataEntryMSB, 12, nil).
+				ControlChange(1, ControllerDataEntryLSB, 0, nil).
+				ControlChange(1, ControllerRPNMSB, 127, nil).
+				ControlChange(1, ControllerRPNLSB, 127, nil),
+		},
+		{
+			"nrpn",
+			NewTrack().SetNRPN(0, 0x0123, 0x2001, nil),
+			NewTrack().
+				ControlChange(0, ControllerNRPNMSB, 0x2, nil).
+				ControlChange(0, ControllerNRPNLSB, 0x23, nil).
+				ControlChange(0, ControllerDataEntryMSB, 0x40, nil).
+				ControlChange(0, ControllerDataEntryLSB, 0x1, nil).
+				ControlChange(0, ControllerRPNMSB, 127, nil).
+				ControlChange(0, ControllerRPNLSB, 127, nil),
+		},
+		{
+			"pitch bend range",
+			NewTrack().PitchBendRange(2, 24, 50, nil),
+			NewTrack().SetRPN(2, RPNPitchBendSensitivity, 24<<7|50, nil),
+		},
+		{
+			"value out of bounds",
+			NewTrack().SetRPN(0, RPNFineTuning, MaxParameterValue+1, nil),
+			nil,
+		},
+		{
+			"parameter out of bounds",
+			NewTrack().SetNRPN(0, RPNNull+1, 0, nil),
+			nil,
+		},
+		{
+			"invalid channel",
+			NewTrack().SetRPN(16, RPNCoarseTuning, 0x40<<7, nil),
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.want == nil {
+				if tt.got.Err() == nil {
+					t.Errorf("Track error = nil, want error")
+				}
+				return
+			}
+			if err := tt.got.Err(); err != nil {
+				t.Fatalf("Track error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.got.Bytes(), tt.want.Bytes()) {
+				t.Errorf("Track = %v, want %v", tt.got.Bytes(), tt.want.Bytes())
+			}
+		})
+	}
+}
+
+func TestTrack_ParameterChanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		track *Track
+		want  []ParameterChange
+	}{
+		{
+			"set parameters",
+			NewTrack().
+				SetRPN(0, RPNMPEConfiguration, 15<<7, TranslateTickTime(96)).
+				SetNRPN(3, 0x0123, 0x2001, TranslateTickTime(96)),
+			[]ParameterChange{
+				{96, 0, true, RPNMPEConfiguration, 15 << 7},
+				{192, 3, false, 0x0123, 0x2001},
+			},
+		},
+		{
+			"coarse only",
+			NewTrack().
+				ControlChange(1, ControllerRPNMSB, 0, nil).
+				ControlChange(1, ControllerRPNLSB, 2, nil).
+				ControlChange(1, ControllerDataEntryMSB, 0x3c, nil).
+				ControlChange(1, ControllerDataEntryMSB, 0x40, TranslateTickTime(10)),
+			[]ParameterChange{
+				{0, 1, true, RPNCoarseTuning, 0x3c << 7},
+				{10, 1, true, RPNCoarseTuning, 0x40 << 7},
+			},
+		},
+		{
+			"fine only",
+			NewTrack().
+				ControlChange(1, ControllerNRPNMSB, 0, nil).
+				ControlChange(1, ControllerNRPNLSB, 5, nil).
+				ControlChange(1, ControllerDataEntryLSB, 0x10, nil),
+			[]ParameterChange{
+				{0, 1, false, 5, 0x10},
+			},
+		},
+		{
+			"no parameter selected",
+			NewTrack().
+				ControlChange(0, ControllerDataEntryMSB, 0x10, nil).
+				SetRPN(0, RPNFineTuning, 0x2000, nil).
+				ControlChange(0, ControllerDataEntryMSB, 0x10, nil),
+			[]ParameterChange{
+				{0, 0, true, RPNFineTuning, 0x2000},
+			},
+		},
+		{
+			"other events",
+			NewTrack().
+				Tempo(120, nil).
+				Volume(0, 100, nil).
+				NoteOn(0, Pitch(60), nil, 0),
+			[]ParameterChange{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.track.ParameterChanges(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Track.ParameterChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package midi
 
 import (
+	"errors"
 	"math"
 )
 
@@ -9,8 +10,111 @@ import (
 // BPM  - beats per minute
 type Timing int64
 
-// MicrosecondsPerMinute constant
-const MicrosecondsPerMinute = 60000000
+const (
+	// MicrosecondsPerMinute constant
+	MicrosecondsPerMinute = 60000000
+	// MaxVLQ is the largest value a variable-length quantity can hold
+	MaxVLQ = 0x0FFFFFFF
+	// MaxMpqn is the largest tempo a set tempo event can hold, in
+	// microseconds per quarter note
+	MaxMpqn = 0xFFFFFF
+	// MinBpm is the slowest tempo a set tempo event can hold
+	MinBpm = float64(MicrosecondsPerMinute) / MaxMpqn
+	// MaxBpm is the fastest tempo a set tempo event can hold
+	MaxBpm = MicrosecondsPerMinute
+	// DefaultMpqn is the tempo of a file without set tempo events, 120 BPM
+	DefaultMpqn = 500000
+)
+
+// SMPTE frame rates for timecode divisions
+const (
+	// SMPTE24 is 24 frames per second
+	SMPTE24 = 24
+	// SMPTE25 is 25 frames per second
+	SMPTE25 = 25
+	// SMPTE30Drop is 30 drop frame, 29.97 frames per second
+	SMPTE30Drop = 29
+	// SMPTE30 is 30 frames per second
+	SMPTE30 = 30
+)
+
+// Division is the time division of a midi file as stored in its header,
+// either metrical (ticks per beat) or timecode (SMPTE frames per second
+// and ticks per frame)
+type Division uint16
+
+// MetricalDivision returns a division of ticks per beat
+func MetricalDivision(ticks int) (Division, error) {
+	if ticks < 1 || ticks >= (1<<15) {
+		return 0, errors.New("ticks per beat must be an integer between 1 and 32767")
+	}
+	return Division(ticks), nil
+}
+
+// SMPTEDivision returns a timecode division
+// fps           - The SMPTE frame rate, one of SMPTE24, SMPTE25, SMPTE30Drop or SMPTE30
+// ticksPerFrame - The ticks per frame, between 1 and 255
+func SMPTEDivision(fps, ticksPerFrame int) (Division, error) {
+	switch fps {
+	case SMPTE24, SMPTE25, SMPTE30Drop, SMPTE30:
+	default:
+		return 0, errors.New("invalid smpte frame rate")
+	}
+	if ticksPerFrame < 1 || ticksPerFrame > 255 {
+		return 0, errors.New("ticks per frame must be an integer between 1 and 255")
+	}
+	// the frame rate is stored as a negative number
+	return Division(uint16(byte(-fps))<<8 | uint16(ticksPerFrame)), nil
+}
+
+// validate returns an error if d is not a valid division
+func (d Division) validate() error {
+	if d.IsSMPTE() {
+		_, err := SMPTEDivision(d.SMPTEFormat(), d.TicksPerFrame())
+		return err
+	}
+	_, err := MetricalDivision(int(d))
+	return err
+}
+
+// IsSMPTE returns whether d is a timecode division
+func (d Division) IsSMPTE() bool {
+	return d&0x8000 != 0
+}
+
+// TicksPerBeat returns the ticks per beat of a metrical division, 0 otherwise
+func (d Division) TicksPerBeat() int {
+	if d.IsSMPTE() {
+		return 0
+	}
+	return int(d)
+}
+
+// SMPTEFormat returns the frame rate of a timecode division
+// as stored in the file (e.g. SMPTE30Drop), 0 otherwise
+func (d Division) SMPTEFormat() int {
+	if !d.IsSMPTE() {
+		return 0
+	}
+	return -int(int8(d >> 8))
+}
+
+// FramesPerSecond returns the actual frame rate of a timecode
+// division, 29.97 for SMPTE30Drop, 0 otherwise
+func (d Division) FramesPerSecond() float64 {
+	if d.SMPTEFormat() == SMPTE30Drop {
+		return 29.97
+	}
+	return float64(d.SMPTEFormat())
+}
+
+// TicksPerFrame returns the ticks per frame of a timecode division, 0 otherwise
+func (d Division) TicksPerFrame() int {
+	if !d.IsSMPTE() {
+		return 0
+	}
+	return int(d & 0xFF)
+}
 
 // MpqnFromBpm converts beats per minute (BPM) to
 // microseconds per quarter note (MPQN)
@@ -25,12 +129,14 @@ func BpmFromMpqn(mpqn Timing) Timing {
 }
 
 // TranslateTickTime translates number of ticks to MIDI timestamp format
-// returning a []byte with the time values
+// returning a []byte with the time values, negative ticks are 0
 func TranslateTickTime(ticks int) []byte {
+	if ticks < 0 {
+		ticks = 0
+	}
 	buffer := ticks & 0x7F
 
-	for ticks != 0 {
-		ticks = ticks >> 7
+	for ticks >>= 7; ticks != 0; ticks >>= 7 {
 		buffer <<= 8
 		buffer |= ((ticks & 0x7F) | 0x80)
 	}
@@ -48,3 +154,25 @@ func TranslateTickTime(ticks int) []byte {
 
 	return bList
 }
+
+// ParseTickTime translates a MIDI timestamp, as returned by
+// TranslateTickTime, to number of ticks, an empty timestamp is 0 ticks
+func ParseTickTime(time []byte) int {
+	ticks := 0
+	for i, b := range time {
+		ticks = ticks<<7 | int(b&0x7F)
+		if b&0x80 == 0 || i == maxVLQBytes-1 {
+			break
+		}
+	}
+	return ticks
+}
+
+// checkDelta returns an error if delta can't be written as the
+// time of an event
+func checkDelta(delta int) error {
+	if delta < 0 || delta > MaxVLQ {
+		return errors.New("delta time out of bounds")
+	}
+	return nil
+}
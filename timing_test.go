@@ -65,7 +65,27 @@ func TestTranslateTickTime(t *testing.T) {
 		{
 			"ticks to time",
 			args{128},
-			[]byte{128, 129, 0},
+			[]byte{0x81, 0x0},
+		},
+		{
+			"single byte",
+			args{0x7f},
+			[]byte{0x7f},
+		},
+		{
+			"zero ticks",
+			args{0},
+			[]byte{0x0},
+		},
+		{
+			"max ticks",
+			args{MaxVLQ},
+			[]byte{0xff, 0xff, 0xff, 0x7f},
+		},
+		{
+			"negative ticks",
+			args{-1},
+			[]byte{0x0},
 		},
 	}
 	for _, tt := range tests {
@@ -76,3 +96,145 @@ func TestTranslateTickTime(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTickTime(t *testing.T) {
+	tests := []struct {
+		name string
+		time []byte
+		want int
+	}{
+		{"empty", nil, 0},
+		{"single byte", []byte{0x7f}, 0x7f},
+		{"two bytes", []byte{0x81, 0x0}, 128},
+		{"max ticks", []byte{0xff, 0xff, 0xff, 0x7f}, MaxVLQ},
+		{"trailing bytes", []byte{0x60, 0x90}, 0x60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTickTime(tt.time); got != tt.want {
+				t.Errorf("ParseTickTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricalDivision(t *testing.T) {
+	tests := []struct {
+		name    string
+		ticks   int
+		want    Division
+		wantErr bool
+	}{
+		{
+			"ticks per beat",
+			480,
+			480,
+			false,
+		},
+		{
+			"zero ticks",
+			0,
+			0,
+			true,
+		},
+		{
+			"too many ticks",
+			1 << 15,
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MetricalDivision(tt.ticks)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MetricalDivision() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("MetricalDivision() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && (got.IsSMPTE() || got.TicksPerBeat() != tt.ticks || got.TicksPerFrame() != 0 || got.FramesPerSecond() != 0) {
+				t.Errorf("MetricalDivision() = %#x is not metrical", got)
+			}
+		})
+	}
+}
+
+func TestSMPTEDivision(t *testing.T) {
+	type args struct {
+		fps           int
+		ticksPerFrame int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Division
+		wantFps float64
+		wantErr bool
+	}{
+		{
+			"24 fps",
+			args{SMPTE24, 4},
+			0xe804,
+			24,
+			false,
+		},
+		{
+			"25 fps",
+			args{SMPTE25, 40},
+			0xe728,
+			25,
+			false,
+		},
+		{
+			"30 drop fps",
+			args{SMPTE30Drop, 80},
+			0xe350,
+			29.97,
+			false,
+		},
+		{
+			"30 fps",
+			args{SMPTE30, 255},
+			0xe2ff,
+			30,
+			false,
+		},
+		{
+			"invalid frame rate",
+			args{23, 4},
+			0,
+			0,
+			true,
+		},
+		{
+			"invalid ticks per frame",
+			args{SMPTE25, 256},
+			0,
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SMPTEDivision(tt.args.fps, tt.args.ticksPerFrame)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SMPTEDivision() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SMPTEDivision() = %#x, want %#x", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.IsSMPTE() || got.TicksPerBeat() != 0 {
+				t.Errorf("SMPTEDivision() = %#x is not timecode", got)
+			}
+			if got.SMPTEFormat() != tt.args.fps || got.TicksPerFrame() != tt.args.ticksPerFrame || got.FramesPerSecond() != tt.wantFps {
+				t.Errorf("SMPTEDivision() = %v fps %v tpf, want %v fps %v tpf", got.FramesPerSecond(), got.TicksPerFrame(), tt.wantFps, tt.args.ticksPerFrame)
+			}
+		})
+	}
+}
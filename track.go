@@ -1,8 +1,9 @@
 package midi
 
 import (
+	"encoding/binary"
 	"errors"
-	"strconv"
+	"io"
 )
 
 var (
@@ -24,6 +25,21 @@ const (
 // Track is a midi track
 type Track struct {
 	events []Event
+	// absolute is set by At, from then on events are placed at
+	// absolute times and sorted when the track is serialized
+	absolute bool
+	// at is the absolute time of the last placed event
+	at     int
+	placed []timedEvent
+	// offs are the note-off events scheduled by AddNote
+	offs []timedEvent
+	// wait is the number of ticks the next event is delayed by
+	wait int
+	// err is the first error of the methods adding events
+	err error
+	// end is the absolute time of the end of track event, the
+	// track ends right after its last event if it is earlier
+	end int
 }
 
 // NewTrack returns a new midi track
@@ -36,30 +52,111 @@ func (t *Track) AddEvent(e Event) error {
 	if e == nil {
 		return errors.New("can't add nil event to track")
 	}
-	t.events = append(t.events, e)
+	t.insert(untyped(e))
 	return nil
 }
 
+// Err returns the first error of the methods adding events to the
+// track, which do nothing once there is one so they can be chained
+// safely. Build events with their constructors (NewNoteOn, NewTempoEvent,
+// NewControlChange...) and add them with AddEvent to validate each one
+func (t *Track) Err() error {
+	return t.err
+}
+
+// At moves the track to an absolute time in ticks, the next event
+// is placed there plus its own delta time and every event after it
+// is relative to the previous one as usual. Events can be placed in
+// any order, the track is sorted by time when serialized
+func (t *Track) At(tick int) *Track {
+	if tick < 0 {
+		return t.fail(errors.New("negative tick"))
+	}
+	if t.err != nil {
+		return t
+	}
+	t.absolute = true
+	t.at = tick
+	t.wait = 0
+	return t
+}
+
+// Wait delays the next event added to the track by a number of ticks,
+// on top of its own delta time
+func (t *Track) Wait(ticks int) *Track {
+	if ticks < 0 {
+		return t.fail(errors.New("negative wait"))
+	}
+	if t.err == nil {
+		t.wait += ticks
+	}
+	return t
+}
+
+// add adds the event returned by one of the constructors to
+// the track, or keeps err as the error of the track
+func (t *Track) add(e Event, err error) *Track {
+	if err != nil {
+		return t.fail(err)
+	}
+	if t.err == nil {
+		t.insert(e)
+	}
+	return t
+}
+
+// fail keeps err as the error of the track unless it already has one
+func (t *Track) fail(err error) *Track {
+	if t.err == nil {
+		t.err = err
+	}
+	return t
+}
+
+// insert adds an event to the track, after the last one
+// or at the current absolute time
+func (t *Track) insert(e Event) {
+	wait := t.wait
+	t.wait = 0
+	if t.absolute {
+		t.at += wait + e.Delta()
+		setTick(e, t.at)
+		t.placed = append(t.placed, timedEvent{t.at, e})
+		return
+	}
+	if wait != 0 {
+		e.SetTime(e.Delta() + wait)
+	}
+	setTick(e, t.now()+e.Delta())
+	t.events = append(t.events, e)
+}
+
+// now returns the absolute time of the last event added to the track
+func (t *Track) now() int {
+	if t.absolute {
+		return t.at
+	}
+	if len(t.events) == 0 {
+		return 0
+	}
+	return t.events[len(t.events)-1].Tick()
+}
+
+// sorted returns the events of the track sorted by time
+func (t *Track) sorted() []Event {
+	if len(t.placed) == 0 && len(t.offs) == 0 {
+		return t.events
+	}
+	return newTrackFromTimeline(t.timeline()).events
+}
+
 // AddNoteOn adds a note-on event to the track
 // channel  - The channel to add the event to
 // p        - The pitch of the note {Note|Pitch}
 // time     - The number of ticks since the previous event, default is 0
 // velocity - The velocity the note was released, default is DefaultVolume
 func (t *Track) AddNoteOn(channel int, p Pitchier, time []byte, velocity int) *Track {
-	p2 := velocity
-	if p2 == 0 {
-		p2 = DefaultVolume
-	}
-	p1, err := EnsurePitch(p)
-	if err != nil {
-		return nil
-	}
-	e, err := NewEvent(time, EventNoteOn, channel, byte(p1), byte(p2))
-	if err != nil {
-		return nil
-	}
-	t.events = append(t.events, e)
-	return t
+	return t.NoteOnAfter(channel, p, ParseTickTime(time), velocity)
 }
 
 // NoteOn adds a note-on event to the track
@@ -71,26 +168,22 @@ func (t *Track) NoteOn(channel int, p Pitchier, time []byte, velocity int) *Trac
 	return t.AddNoteOn(channel, p, time, velocity)
 }
 
+// NoteOnAfter adds a note-on event to the track
+// channel  - The channel to add the event to
+// p        - The pitch of the note {Note|Pitch}
+// delta    - The number of ticks since the previous event
+// velocity - The velocity the note was released, default is DefaultVolume
+func (t *Track) NoteOnAfter(channel int, p Pitchier, delta, velocity int) *Track {
+	return t.add(NewNoteOnAfter(delta, channel, p, velocity))
+}
+
 // AddNoteOff adds a note-off event to the track
 // channel  - The channel to add the event to
 // p        - The pitch of the note {Note|Pitch}
 // time     - The number of ticks since the previous event, default is 0
 // velocity - The velocity the note was released, default is DefaultVolume
 func (t *Track) AddNoteOff(channel int, p Pitchier, time []byte, velocity int) *Track {
-	p2 := velocity
-	if p2 == 0 {
-		p2 = DefaultVolume
-	}
-	p1, err := EnsurePitch(p)
-	if err != nil {
-		return nil
-	}
-	e, err := NewEvent(time, EventNoteOff, channel, byte(p1), byte(p2))
-	if err != nil {
-		return nil
-	}
-	t.events = append(t.events, e)
-	return t
+	return t.NoteOffAfter(channel, p, ParseTickTime(time), velocity)
 }
 
 // NoteOff adds a note-off event to the track
@@ -102,21 +195,30 @@ func (t *Track) NoteOff(channel int, p Pitchier, time []byte, velocity int) *Tra
 	return t.AddNoteOff(channel, p, time, velocity)
 }
 
-// AddNote adds a note-on and -off event to the track
+// NoteOffAfter adds a note-off event to the track
+// channel  - The channel to add the event to
+// p        - The pitch of the note {Note|Pitch}
+// delta    - The number of ticks since the previous event
+// velocity - The velocity the note was released, default is DefaultVolume
+func (t *Track) NoteOffAfter(channel int, p Pitchier, delta, velocity int) *Track {
+	return t.add(NewNoteOffAfter(delta, channel, p, velocity))
+}
+
+// AddNote adds a note-on event to the track and schedules its note-off
+// dur ticks later, the next event is still relative to the note-on so
+// notes can overlap. A note-off goes before any other event at its time
 // channel  - The channel to add the event to
 // p        - The pitch of the note {Note|Pitch}
 // dur      - The duration of the note, is ticks
 // time     - The number of ticks since the previous event, default is 0
 // velocity - The velocity the note was released, default is DefaultVolume
 func (t *Track) AddNote(channel int, p Pitchier, dur int, time []byte, velocity int) *Track {
-	t.AddNoteOn(channel, p, time, velocity)
-	if dur != 0 {
-		t.AddNoteOff(channel, p, TranslateTickTime(dur), velocity)
-	}
-	return t
+	return t.NoteAfter(channel, p, dur, ParseTickTime(time), velocity)
 }
 
-// Note adds a note-on and -off event to the track
+// Note adds a note-on event to the track and schedules its note-off
+// dur ticks later, the next event is still relative to the note-on so
+// notes can overlap. A note-off goes before any other event at its time
 // channel  - The channel to add the event to
 // p        - The pitch of the note {Note|Pitch}
 // dur      - The duration of the note, is ticks
@@ -126,6 +228,32 @@ func (t *Track) Note(channel int, p Pitchier, dur int, time []byte, velocity int
 	return t.AddNote(channel, p, dur, time, velocity)
 }
 
+// NoteAfter adds a note-on event to the track and schedules its note-off
+// dur ticks later, the next event is still relative to the note-on so
+// notes can overlap. A note-off goes before any other event at its time
+// channel  - The channel to add the event to
+// p        - The pitch of the note {Note|Pitch}
+// dur      - The duration of the note, is ticks
+// delta    - The number of ticks since the previous event
+// velocity - The velocity the note was released, default is DefaultVolume
+func (t *Track) NoteAfter(channel int, p Pitchier, dur, delta, velocity int) *Track {
+	if dur < 0 {
+		return t.fail(errors.New("negative duration"))
+	}
+	off, err := NewNoteOffAfter(dur, channel, p, velocity)
+	if err != nil {
+		return t.fail(err)
+	}
+	if t.NoteOnAfter(channel, p, delta, velocity).err != nil {
+		return t
+	}
+	if dur != 0 {
+		off.setTick(t.now() + dur)
+		t.offs = append(t.offs, timedEvent{off.tick, off})
+	}
+	return t
+}
+
 // AddChord adds a note-on and -off event to the track for each
 // pitch is chord
 // channel  - The channel to add the event to
@@ -133,12 +261,15 @@ func (t *Track) Note(channel int, p Pitchier, dur int, time []byte, velocity int
 // dur      - The duration of the note, is ticks
 // velocity - The velocity the note was released, default is DefaultVolume
 func (t *Track) AddChord(channel int, chord []Pitchier, dur, velocity int) *Track {
+	if dur < 0 {
+		return t.fail(errors.New("negative duration"))
+	}
 	for _, note := range chord {
 		t.NoteOn(channel, note, nil, velocity)
 	}
 	for i, note := range chord {
 		if i == 0 {
-			t.NoteOff(channel, note, TranslateTickTime(dur), velocity)
+			t.NoteOffAfter(channel, note, dur, velocity)
 		} else {
 			t.NoteOff(channel, note, nil, 0)
 		}
@@ -156,17 +287,83 @@ func (t *Track) Chord(channel int, chord []Pitchier, dur, velocity int) *Track {
 	return t.AddChord(channel, chord, dur, velocity)
 }
 
+// NewNoteOn returns a new note-on event
+// time     - The number of ticks since the previous event, default is 0
+// channel  - The channel of the event
+// p        - The pitch of the note {Note|Pitch}
+// velocity - The velocity of the note, default is DefaultVolume
+func NewNoteOn(time []byte, channel int, p Pitchier, velocity int) (*NormalEvent, error) {
+	return NewNoteOnAfter(ParseTickTime(time), channel, p, velocity)
+}
+
+// NewNoteOnAfter returns a new note-on event
+// delta    - The number of ticks since the previous event
+// channel  - The channel of the event
+// p        - The pitch of the note {Note|Pitch}
+// velocity - The velocity of the note, default is DefaultVolume
+func NewNoteOnAfter(delta, channel int, p Pitchier, velocity int) (*NormalEvent, error) {
+	return newNoteEvent(delta, EventNoteOn, channel, p, velocity)
+}
+
+// NewNoteOff returns a new note-off event
+// time     - The number of ticks since the previous event, default is 0
+// channel  - The channel of the event
+// p        - The pitch of the note {Note|Pitch}
+// velocity - The velocity the note was released, default is DefaultVolume
+func NewNoteOff(time []byte, channel int, p Pitchier, velocity int) (*NormalEvent, error) {
+	return NewNoteOffAfter(ParseTickTime(time), channel, p, velocity)
+}
+
+// NewNoteOffAfter returns a new note-off event
+// delta    - The number of ticks since the previous event
+// channel  - The channel of the event
+// p        - The pitch of the note {Note|Pitch}
+// velocity - The velocity the note was released, default is DefaultVolume
+func NewNoteOffAfter(delta, channel int, p Pitchier, velocity int) (*NormalEvent, error) {
+	return newNoteEvent(delta, EventNoteOff, channel, p, velocity)
+}
+
+// NewProgramChange returns a new program change event
+// time       - The number of ticks since the previous event, default is 0
+// channel    - The channel of the event
+// instrument - The instrument, between 0 and 127
+func NewProgramChange(time []byte, channel int, instrument byte) (*NormalEvent, error) {
+	return NewProgramChangeAfter(ParseTickTime(time), channel, instrument)
+}
+
+// NewProgramChangeAfter returns a new program change event
+// delta      - The number of ticks since the previous event
+// channel    - The channel of the event
+// instrument - The instrument, between 0 and 127
+func NewProgramChangeAfter(delta, channel int, instrument byte) (*NormalEvent, error) {
+	if instrument > 0x7F {
+		return nil, errors.New("instrument out of bounds")
+	}
+	return NewEventAfter(delta, EventProgramChange, channel, instrument, 0)
+}
+
+// newNoteEvent returns a new note-on or -off event
+func newNoteEvent(delta int, _type EventType, channel int, p Pitchier, velocity int) (*NormalEvent, error) {
+	if velocity < 0 || velocity > 0x7F {
+		return nil, errors.New("velocity out of bounds")
+	}
+	p2 := velocity
+	if p2 == 0 {
+		p2 = DefaultVolume
+	}
+	p1, err := EnsurePitch(p)
+	if err != nil {
+		return nil, err
+	}
+	return NewEventAfter(delta, _type, channel, byte(p1), byte(p2))
+}
+
 // SetInstrument sets the instrument for the track
 // channel    - The channel to add the event to
 // instrument - The instrument to set it to
 // time       - The number of ticks since the previous event, default is 0
 func (t *Track) SetInstrument(channel int, instrument byte, time []byte) *Track {
-	e, err := NewEvent(time, EventProgramChange, channel, instrument, 0)
-	if err != nil {
-		return nil
-	}
-	t.events = append(t.events, e)
-	return t
+	return t.InstrumentAfter(channel, instrument, ParseTickTime(time))
 }
 
 // Instrument sets the instrument for the track
@@ -177,13 +374,19 @@ func (t *Track) Instrument(channel int, instrument byte, time []byte) *Track {
 	return t.SetInstrument(channel, instrument, time)
 }
 
+// InstrumentAfter sets the instrument for the track
+// channel    - The channel to add the event to
+// instrument - The instrument to set it to
+// delta      - The number of ticks since the previous event
+func (t *Track) InstrumentAfter(channel int, instrument byte, delta int) *Track {
+	return t.add(NewProgramChangeAfter(delta, channel, instrument))
+}
+
 // SetTempo sets the tempo for the track
 // bpm  - The new beats per minute
 // time - The number of ticks since the previous event, default is 0
 func (t *Track) SetTempo(bpm Timing, time []byte) *Track {
-	e, _ := NewMetaEvent(time, EventTempo, MpqnFromBpm(bpm))
-	t.events = append(t.events, e)
-	return t
+	return t.TempoAfter(bpm, ParseTickTime(time))
 }
 
 // Tempo sets the tempo for the track
@@ -193,35 +396,274 @@ func (t *Track) Tempo(bpm Timing, time []byte) *Track {
 	return t.SetTempo(bpm, time)
 }
 
-// Bytes returns the serialized track
+// TempoAfter sets the tempo for the track
+// bpm   - The new beats per minute
+// delta - The number of ticks since the previous event
+func (t *Track) TempoAfter(bpm Timing, delta int) *Track {
+	return t.add(NewTempoEventAfter(delta, float64(bpm)))
+}
+
+// SetTimeSignature sets the time signature for the track
+// num            - The numerator of the time signature
+// denomPow2      - The denominator as a power of two, 2 for a quarter note
+// clocksPerClick - The midi clocks per metronome click, 24 for a quarter note
+// n32PerQuarter  - The notated 32nd notes per midi quarter note, usually 8
+// time           - The number of ticks since the previous event, default is 0
+func (t *Track) SetTimeSignature(num, denomPow2, clocksPerClick, n32PerQuarter byte, time []byte) *Track {
+	return t.TimeSignatureAfter(num, denomPow2, clocksPerClick, n32PerQuarter, ParseTickTime(time))
+}
+
+// TimeSignature sets the time signature for the track
+// num            - The numerator of the time signature
+// denomPow2      - The denominator as a power of two, 2 for a quarter note
+// clocksPerClick - The midi clocks per metronome click, 24 for a quarter note
+// n32PerQuarter  - The notated 32nd notes per midi quarter note, usually 8
+// time           - The number of ticks since the previous event, default is 0
+func (t *Track) TimeSignature(num, denomPow2, clocksPerClick, n32PerQuarter byte, time []byte) *Track {
+	return t.SetTimeSignature(num, denomPow2, clocksPerClick, n32PerQuarter, time)
+}
+
+// TimeSignatureAfter sets the time signature for the track
+// num            - The numerator of the time signature
+// denomPow2      - The denominator as a power of two, 2 for a quarter note
+// clocksPerClick - The midi clocks per metronome click, 24 for a quarter note
+// n32PerQuarter  - The notated 32nd notes per midi quarter note, usually 8
+// delta          - The number of ticks since the previous event
+func (t *Track) TimeSignatureAfter(num, denomPow2, clocksPerClick, n32PerQuarter byte, delta int) *Track {
+	return t.add(NewTimeSignatureAfter(delta, num, denomPow2, clocksPerClick, n32PerQuarter))
+}
+
+// SetKeySignature sets the key signature for the track
+// sharpsFlats - The number of sharps (positive) or flats (negative), from -7 to 7
+// minor       - Whether the key is minor
+// time        - The number of ticks since the previous event, default is 0
+func (t *Track) SetKeySignature(sharpsFlats int8, minor bool, time []byte) *Track {
+	return t.KeySignatureAfter(sharpsFlats, minor, ParseTickTime(time))
+}
+
+// KeySignature sets the key signature for the track
+// sharpsFlats - The number of sharps (positive) or flats (negative), from -7 to 7
+// minor       - Whether the key is minor
+// time        - The number of ticks since the previous event, default is 0
+func (t *Track) KeySignature(sharpsFlats int8, minor bool, time []byte) *Track {
+	return t.SetKeySignature(sharpsFlats, minor, time)
+}
+
+// KeySignatureAfter sets the key signature for the track
+// sharpsFlats - The number of sharps (positive) or flats (negative), from -7 to 7
+// minor       - Whether the key is minor
+// delta       - The number of ticks since the previous event
+func (t *Track) KeySignatureAfter(sharpsFlats int8, minor bool, delta int) *Track {
+	return t.add(NewKeySignatureAfter(delta, sharpsFlats, minor))
+}
+
+// AddMarker adds a marker to the track
+// text - The name of the marker
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) AddMarker(text string, time []byte) *Track {
+	return t.MarkerAfter(text, ParseTickTime(time))
+}
+
+// Marker adds a marker to the track
+// text - The name of the marker
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) Marker(text string, time []byte) *Track {
+	return t.AddMarker(text, time)
+}
+
+// MarkerAfter adds a marker to the track
+// text  - The name of the marker
+// delta - The number of ticks since the previous event
+func (t *Track) MarkerAfter(text string, delta int) *Track {
+	return t.add(NewMarkerAfter(delta, text))
+}
+
+// AddCuePoint adds a cue point to the track
+// text - The description of the cue
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) AddCuePoint(text string, time []byte) *Track {
+	return t.CuePointAfter(text, ParseTickTime(time))
+}
+
+// CuePoint adds a cue point to the track
+// text - The description of the cue
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) CuePoint(text string, time []byte) *Track {
+	return t.AddCuePoint(text, time)
+}
+
+// CuePointAfter adds a cue point to the track
+// text  - The description of the cue
+// delta - The number of ticks since the previous event
+func (t *Track) CuePointAfter(text string, delta int) *Track {
+	return t.add(NewCuePointAfter(delta, text))
+}
+
+// AddLyric adds a lyric to the track
+// text - The lyric, usually a single syllable
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) AddLyric(text string, time []byte) *Track {
+	return t.LyricAfter(text, ParseTickTime(time))
+}
+
+// Lyric adds a lyric to the track
+// text - The lyric, usually a single syllable
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) Lyric(text string, time []byte) *Track {
+	return t.AddLyric(text, time)
+}
+
+// LyricAfter adds a lyric to the track
+// text  - The lyric, usually a single syllable
+// delta - The number of ticks since the previous event
+func (t *Track) LyricAfter(text string, delta int) *Track {
+	return t.add(NewLyricAfter(delta, text))
+}
+
+// AddText adds a text event to the track
+// text - The text
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) AddText(text string, time []byte) *Track {
+	return t.TextAfter(text, ParseTickTime(time))
+}
+
+// Text adds a text event to the track
+// text - The text
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) Text(text string, time []byte) *Track {
+	return t.AddText(text, time)
+}
+
+// TextAfter adds a text event to the track
+// text  - The text
+// delta - The number of ticks since the previous event
+func (t *Track) TextAfter(text string, delta int) *Track {
+	return t.add(NewTextAfter(delta, text))
+}
+
+// SetCopyright sets the copyright notice of the track
+// text - The copyright notice
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) SetCopyright(text string, time []byte) *Track {
+	return t.CopyrightAfter(text, ParseTickTime(time))
+}
+
+// Copyright sets the copyright notice of the track
+// text - The copyright notice
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) Copyright(text string, time []byte) *Track {
+	return t.SetCopyright(text, time)
+}
+
+// CopyrightAfter sets the copyright notice of the track
+// text  - The copyright notice
+// delta - The number of ticks since the previous event
+func (t *Track) CopyrightAfter(text string, delta int) *Track {
+	return t.add(NewCopyrightAfter(delta, text))
+}
+
+// SetName sets the name of the track
+// text - The name
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) SetName(text string, time []byte) *Track {
+	return t.NameAfter(text, ParseTickTime(time))
+}
+
+// Name sets the name of the track
+// text - The name
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) Name(text string, time []byte) *Track {
+	return t.SetName(text, time)
+}
+
+// NameAfter sets the name of the track
+// text  - The name
+// delta - The number of ticks since the previous event
+func (t *Track) NameAfter(text string, delta int) *Track {
+	return t.add(NewTrackNameAfter(delta, text))
+}
+
+// SysEx adds a system exclusive event to the track
+// data - The message, see NewSysExEvent
+// time - The number of ticks since the previous event, default is 0
+func (t *Track) SysEx(data []byte, time []byte) *Track {
+	return t.SysExAfter(data, ParseTickTime(time))
+}
+
+// SysExAfter adds a system exclusive event to the track
+// data  - The message, see NewSysExEvent
+// delta - The number of ticks since the previous event
+func (t *Track) SysExAfter(data []byte, delta int) *Track {
+	return t.add(NewSysExEventAfter(delta, data))
+}
+
+// Bytes returns the serialized track, the events not added because
+// of an error are missing from it, so check Err first or use WriteTo
 func (t *Track) Bytes() Codes {
-	trackLength := 0
-	bytes := Codes{}
+	events := t.sorted()
+	end := t.endBytes(events)
+	bytes := chunkHeader(events, end)
 
-	addEventBytes := func(e Event) {
-		b := e.Bytes()
-		trackLength += len(b)
-		bytes = append(bytes, b...)
+	for _, event := range events {
+		bytes = append(bytes, event.Bytes()...)
 	}
 
-	for _, event := range t.events {
-		addEventBytes(event)
-	}
+	return append(bytes, end...)
+}
 
-	// Add the end-of-track bytes to the sum of bytes for the track, since
-	// they are counted (unlike the start-of-track ones)
-	trackLength += len(TrackEndBytes)
+// WriteTo writes the serialized track to w one event at a time
+func (t *Track) WriteTo(w io.Writer) (int64, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	events := t.sorted()
+	end := t.endBytes(events)
+	n, err := w.Write(chunkHeader(events, end))
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	for _, event := range events {
+		n, err = w.Write(event.Bytes())
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	n, err = w.Write(end)
+	return total + int64(n), err
+}
 
-	s := strconv.Itoa(trackLength)
-	if len(s) > 16 {
-		s = s[:16]
+// endBytes returns the end of track event following the given events,
+// which is TrackEndBytes unless the track was decoded from a file
+// ending it later than its last event
+func (t *Track) endBytes(events []Event) Codes {
+	last := 0
+	for _, e := range events {
+		last += e.Delta()
+	}
+	if t.end <= last {
+		return TrackEndBytes
 	}
+	return append(Codes(TranslateTickTime(t.end-last)), TrackEndBytes[1:]...)
+}
+
+// chunkHeader returns the start-of-track bytes followed by
+// the length of a track with the given events and end
+func chunkHeader(events []Event, end Codes) Codes {
+	trackLength := 0
 
-	lengthBytes := GetCodes(s, 4)
+	for _, event := range events {
+		trackLength += len(event.Bytes())
+	}
 
-	tmp := append(TrackStartBytes, lengthBytes...)
+	// Add the end-of-track bytes to the sum of bytes for the track, since
+	// they are counted (unlike the start-of-track ones)
+	trackLength += len(end)
 
-	bytes = append(bytes, TrackEndBytes...)
+	bytes := append(Codes{}, TrackStartBytes...)
+	lengthBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBytes, uint32(trackLength))
 
-	return append(tmp, bytes...)
+	return append(bytes, lengthBytes...)
 }
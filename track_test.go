@@ -1,6 +1,9 @@
 package midi
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
 	"reflect"
 	"testing"
 )
@@ -29,7 +32,7 @@ func TestTrack_AddEvent(t *testing.T) {
 		e Event
 	}
 	ne, _ := NewEvent(nil, EventNoteOff, 0, 1, 0)
-	me, _ := NewMetaEvent(nil, EventSequence, Timing(0))
+	me, _ := NewMetaEvent(nil, EventTempo, Timing(DefaultMpqn))
 	tests := []struct {
 		name    string
 		t       *Track
@@ -71,37 +74,33 @@ func TestTrack_NoteOn(t *testing.T) {
 		time     []byte
 		velocity int
 	}
-	tr := NewTrack()
 	p, _ := PitchFromNote("c5")
 	tests := []struct {
-		name string
-		t    *Track
-		args args
-		want *Track
+		name    string
+		args    args
+		wantErr bool
 	}{
 		{
 			"invalid channel",
-			nil,
 			args{-1, Note("c4"), nil, 0},
-			nil,
+			true,
 		},
 		{
 			"invalid pitchier",
-			nil,
 			args{0, Note("c"), nil, 0},
-			nil,
+			true,
 		},
 		{
 			"note-on",
-			tr,
 			args{0, p, nil, 0},
-			tr,
+			false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.t.NoteOn(tt.args.channel, tt.args.p, tt.args.time, tt.args.velocity); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Track.NoteOn() = %v, want %v", got, tt.want)
+			tr := NewTrack()
+			if got := tr.NoteOn(tt.args.channel, tt.args.p, tt.args.time, tt.args.velocity); got != tr || (got.Err() != nil) != tt.wantErr {
+				t.Errorf("Track.NoteOn() error = %v, wantErr %v", got.Err(), tt.wantErr)
 			}
 		})
 	}
@@ -114,37 +113,33 @@ func TestTrack_NoteOff(t *testing.T) {
 		time     []byte
 		velocity int
 	}
-	tr := NewTrack()
 	p, _ := PitchFromNote("c5")
 	tests := []struct {
-		name string
-		t    *Track
-		args args
-		want *Track
+		name    string
+		args    args
+		wantErr bool
 	}{
 		{
 			"invalid channel",
-			nil,
 			args{-1, Note("c4"), nil, 0},
-			nil,
+			true,
 		},
 		{
 			"invalid pitchier",
-			nil,
 			args{0, Note("c"), nil, 0},
-			nil,
+			true,
 		},
 		{
 			"note-off",
-			tr,
 			args{0, p, nil, 0},
-			tr,
+			false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.t.NoteOff(tt.args.channel, tt.args.p, tt.args.time, tt.args.velocity); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Track.NoteOff() = %v, want %v", got, tt.want)
+			tr := NewTrack()
+			if got := tr.NoteOff(tt.args.channel, tt.args.p, tt.args.time, tt.args.velocity); got != tr || (got.Err() != nil) != tt.wantErr {
+				t.Errorf("Track.NoteOff() error = %v, wantErr %v", got.Err(), tt.wantErr)
 			}
 		})
 	}
@@ -158,35 +153,73 @@ func TestTrack_Note(t *testing.T) {
 		time     []byte
 		velocity int
 	}
-	tr := NewTrack()
 	tests := []struct {
-		name string
-		t    *Track
-		args args
-		want *Track
+		name    string
+		args    args
+		wantErr bool
 	}{
 		{
 			"0 dur",
-			tr,
 			args{0, Pitch(60), 0, nil, 0},
-			tr,
+			false,
 		},
 		{
 			"1+ dur",
-			tr,
 			args{0, Pitch(60), 1, nil, 0},
-			tr,
+			false,
+		},
+		{
+			"negative dur",
+			args{0, Pitch(60), -1, nil, 0},
+			true,
+		},
+		{
+			"invalid pitch",
+			args{0, Note("c"), 1, nil, 0},
+			true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.t.Note(tt.args.channel, tt.args.p, tt.args.dur, tt.args.time, tt.args.velocity); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Track.Note() = %v, want %v", got, tt.want)
+			tr := NewTrack()
+			if got := tr.Note(tt.args.channel, tt.args.p, tt.args.dur, tt.args.time, tt.args.velocity); got != tr || (got.Err() != nil) != tt.wantErr {
+				t.Errorf("Track.Note() error = %v, wantErr %v", got.Err(), tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestTrack_NoteOverlap(t *testing.T) {
+	// a held bass note under two melody notes, then a repeated note
+	tr := NewTrack().
+		Note(1, Pitch(48), 192, nil, 0).
+		Note(0, Pitch(72), 96, nil, 0).
+		Note(0, Pitch(74), 96, TranslateTickTime(96), 0).
+		Note(0, Pitch(74), 48, TranslateTickTime(96), 0).
+		Text("end", TranslateTickTime(96))
+	want := NewTrack().
+		NoteOn(1, Pitch(48), nil, 0).
+		NoteOn(0, Pitch(72), nil, 0).
+		NoteOff(0, Pitch(72), TranslateTickTime(96), 0).
+		NoteOn(0, Pitch(74), nil, 0).
+		NoteOff(1, Pitch(48), TranslateTickTime(96), 0).
+		NoteOff(0, Pitch(74), nil, 0).
+		NoteOn(0, Pitch(74), nil, 0).
+		NoteOff(0, Pitch(74), TranslateTickTime(48), 0).
+		Text("end", TranslateTickTime(48))
+	if got := tr.Bytes(); !reflect.DeepEqual(got, want.Bytes()) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want.Bytes())
+	}
+	// pending note-offs are written even if nothing follows them
+	tr = NewTrack().Note(0, Pitch(60), 96, TranslateTickTime(10), 0)
+	want = NewTrack().
+		NoteOn(0, Pitch(60), TranslateTickTime(10), 0).
+		NoteOff(0, Pitch(60), TranslateTickTime(96), 0)
+	if got := tr.Bytes(); !reflect.DeepEqual(got, want.Bytes()) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want.Bytes())
+	}
+}
+
 func TestTrack_Chord(t *testing.T) {
 	type args struct {
 		channel  int
@@ -232,30 +265,27 @@ func TestTrack_Instrument(t *testing.T) {
 		instrument byte
 		time       []byte
 	}
-	tr := NewTrack()
 	tests := []struct {
-		name string
-		t    *Track
-		args args
-		want *Track
+		name    string
+		args    args
+		wantErr bool
 	}{
 		{
 			"invalid channel",
-			nil,
 			args{-1, 0, nil},
-			nil,
+			true,
 		},
 		{
 			"instrument event",
-			tr,
 			args{0, 0, nil},
-			tr,
+			false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.t.Instrument(tt.args.channel, tt.args.instrument, tt.args.time); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Track.Instrument() = %v, want %v", got, tt.want)
+			tr := NewTrack()
+			if got := tr.Instrument(tt.args.channel, tt.args.instrument, tt.args.time); got != tr || (got.Err() != nil) != tt.wantErr {
+				t.Errorf("Track.Instrument() error = %v, wantErr %v", got.Err(), tt.wantErr)
 			}
 		})
 	}
@@ -266,24 +296,27 @@ func TestTrack_Tempo(t *testing.T) {
 		bpm  Timing
 		time []byte
 	}
-	tr := NewTrack()
 	tests := []struct {
-		name string
-		t    *Track
-		args args
-		want *Track
+		name    string
+		args    args
+		wantErr bool
 	}{
 		{
 			"set tempo event",
-			tr,
 			args{200, nil},
-			tr,
+			false,
+		},
+		{
+			"invalid tempo",
+			args{0, nil},
+			true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.t.Tempo(tt.args.bpm, tt.args.time); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Track.Tempo() = %v, want %v", got, tt.want)
+			tr := NewTrack()
+			if got := tr.Tempo(tt.args.bpm, tt.args.time); got != tr || (got.Err() != nil) != tt.wantErr {
+				t.Errorf("Track.Tempo() error = %v, wantErr %v", got.Err(), tt.wantErr)
 			}
 		})
 	}
@@ -311,10 +344,10 @@ func TestTrack_Bytes(t *testing.T) {
 			"track to codes",
 			tr,
 			Codes{
-				0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x29,
-				0x0, 0x90, 0x3c, 0x5a, 0x0, 0x90, 0x48, 0x5a,
-				0x0, 0x90, 0x30, 0x5a, 0x80, 0x4, 0x80, 0x48,
-				0x5a, 0x0, 0x80, 0x30, 0x5a, 0x0, 0x80, 0x24,
+				0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x1c,
+				0x0, 0x90, 0x3c, 0x5a, 0x0, 0x92, 0x48, 0x5a,
+				0x0, 0x92, 0x30, 0x5a, 0x4, 0x82, 0x48,
+				0x5a, 0x0, 0x82, 0x30, 0x5a, 0x0, 0x83, 0x24,
 				0x5a, 0x0, 0xff, 0x2f, 0x0,
 			},
 		},
@@ -327,3 +360,346 @@ func TestTrack_Bytes(t *testing.T) {
 		})
 	}
 }
+
+// limitedWriter fails once more than n bytes are written
+type limitedWriter struct {
+	n int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, io.ErrShortWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestTrack_WriteTo(t *testing.T) {
+	tr := NewTrack().
+		NoteOn(0, Note("c4"), nil, 0).
+		Chord(2, []Pitchier{Note("c5"), Note("c3")}, 4, 0)
+	tests := []struct {
+		name    string
+		w       *limitedWriter
+		want    int64
+		wantErr bool
+	}{
+		{
+			"write track",
+			&limitedWriter{64},
+			int64(len(tr.Bytes())),
+			false,
+		},
+		{
+			"short write",
+			&limitedWriter{10},
+			10,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tr.WriteTo(tt.w)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Track.WriteTo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Track.WriteTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrack_SysEx(t *testing.T) {
+	type args struct {
+		data []byte
+		time []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			"invalid data",
+			args{[]byte{0x80}, nil},
+			true,
+		},
+		{
+			"gs reset",
+			args{GSReset, nil},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTrack()
+			if got := tr.SysEx(tt.args.data, tt.args.time); got != tr || (got.Err() != nil) != tt.wantErr {
+				t.Errorf("Track.SysEx() error = %v, wantErr %v", got.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrack_MetaHelpers(t *testing.T) {
+	tr := NewTrack().
+		Name("piano", nil).
+		Copyright("(c)", nil).
+		Text("intro", nil).
+		TimeSignature(3, 2, 24, 8, nil).
+		KeySignature(-1, false, nil).
+		Marker("A", TranslateTickTime(96)).
+		CuePoint("go", nil).
+		Lyric("la", nil)
+	want := Codes{
+		0x4d, 0x54, 0x72, 0x6b, 0x0, 0x0, 0x0, 0x3c,
+		0x0, 0xff, 0x3, 0x5, 0x70, 0x69, 0x61, 0x6e,
+		0x6f, 0x0, 0xff, 0x2, 0x3, 0x28, 0x63, 0x29,
+		0x0, 0xff, 0x1, 0x5, 0x69, 0x6e, 0x74, 0x72,
+		0x6f, 0x0, 0xff, 0x58, 0x4, 0x3, 0x2, 0x18,
+		0x8, 0x0, 0xff, 0x59, 0x2, 0xff, 0x0, 0x60,
+		0xff, 0x6, 0x1, 0x41, 0x0, 0xff, 0x7, 0x2,
+		0x67, 0x6f, 0x0, 0xff, 0x5, 0x2, 0x6c, 0x61,
+		0x0, 0xff, 0x2f, 0x0,
+	}
+	if got := tr.Bytes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want)
+	}
+	tests := []struct {
+		name string
+		got  *Track
+	}{
+		{"invalid time signature", NewTrack().TimeSignature(0, 2, 24, 8, nil)},
+		{"invalid key signature", NewTrack().KeySignature(8, false, nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.Err() == nil {
+				t.Errorf("Track.Err() = nil, want error")
+			}
+		})
+	}
+}
+
+func TestTrack_At(t *testing.T) {
+	// a pad held for two beats under a melody, written part by part
+	tr := NewTrack().
+		At(0).Note(0, Pitch(72), 96, nil, 0).Note(0, Pitch(74), 96, TranslateTickTime(96), 0).
+		At(0).Note(1, Pitch(48), 192, nil, 0).
+		At(48).Text("pickup", nil)
+	want := NewTrack().
+		NoteOn(0, Pitch(72), nil, 0).
+		NoteOn(1, Pitch(48), nil, 0).
+		Text("pickup", TranslateTickTime(48)).
+		NoteOff(0, Pitch(72), TranslateTickTime(48), 0).
+		NoteOn(0, Pitch(74), nil, 0).
+		NoteOff(0, Pitch(74), TranslateTickTime(96), 0).
+		NoteOff(1, Pitch(48), nil, 0)
+	if got := tr.Bytes(); !reflect.DeepEqual(got, want.Bytes()) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want.Bytes())
+	}
+	var b bytes.Buffer
+	if _, err := tr.WriteTo(&b); err != nil || !reflect.DeepEqual(Codes(b.Bytes()), want.Bytes()) {
+		t.Errorf("Track.WriteTo() = %v, %v, want %v", b.Bytes(), err, want.Bytes())
+	}
+	// serializing must not change the placed events
+	if got := tr.Bytes(); !reflect.DeepEqual(got, want.Bytes()) {
+		t.Errorf("Track.Bytes() = %v on second call, want %v", got, want.Bytes())
+	}
+	// events added before At keep their times
+	mixed := NewTrack().
+		Tempo(120, nil).
+		Tempo(60, TranslateTickTime(96)).
+		At(48).Text("half", nil)
+	want = NewTrack().
+		Tempo(120, nil).
+		Text("half", TranslateTickTime(48)).
+		Tempo(60, TranslateTickTime(48))
+	if got := mixed.Bytes(); !reflect.DeepEqual(got, want.Bytes()) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want.Bytes())
+	}
+	if err := NewTrack().At(-1).Err(); err == nil {
+		t.Errorf("Track.At(-1).Err() = nil, want error")
+	}
+}
+
+func TestTrack_Err(t *testing.T) {
+	tr := NewTrack().
+		NoteOn(0, Pitch(60), nil, 0).
+		NoteOn(16, Pitch(62), nil, 0).
+		Instrument(0, 200, nil).
+		Chord(0, []Pitchier{Pitch(64), Pitch(67)}, 96, 0)
+	if err := tr.Err(); err == nil || err.Error() != "channel out of bounds" {
+		t.Errorf("Track.Err() = %v, want the first error", err)
+	}
+	// the events after the first error are dropped
+	if got, want := tr.Bytes(), NewTrack().NoteOn(0, Pitch(60), nil, 0).Bytes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want)
+	}
+	if _, err := tr.WriteTo(ioutil.Discard); err != tr.Err() {
+		t.Errorf("Track.WriteTo() error = %v, want %v", err, tr.Err())
+	}
+	if err := NewTrack().Chord(0, []Pitchier{Pitch(60)}, -1, 0).Err(); err == nil {
+		t.Errorf("Track.Chord() error = nil, want error")
+	}
+	if err := NewTrack().Note(0, Pitch(60), 96, nil, 0).Err(); err != nil {
+		t.Errorf("Track.Err() = %v, want nil", err)
+	}
+}
+
+func TestNewNoteEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		got     func() (*NormalEvent, error)
+		want    Codes
+		wantErr bool
+	}{
+		{
+			"note-on",
+			func() (*NormalEvent, error) { return NewNoteOn(nil, 1, Pitch(60), 100) },
+			Codes{0x0, 0x91, 0x3c, 0x64},
+			false,
+		},
+		{
+			"note-off default velocity",
+			func() (*NormalEvent, error) { return NewNoteOff(TranslateTickTime(96), 1, Note("c4"), 0) },
+			Codes{0x60, 0x81, 0x3c, 0x5a},
+			false,
+		},
+		{
+			"invalid velocity",
+			func() (*NormalEvent, error) { return NewNoteOn(nil, 1, Pitch(60), 128) },
+			nil,
+			true,
+		},
+		{
+			"invalid pitch",
+			func() (*NormalEvent, error) { return NewNoteOff(nil, 1, Note("c"), 0) },
+			nil,
+			true,
+		},
+		{
+			"program change",
+			func() (*NormalEvent, error) { return NewProgramChange(nil, 9, 0x10) },
+			Codes{0x0, 0xc9, 0x10},
+			false,
+		},
+		{
+			"invalid instrument",
+			func() (*NormalEvent, error) { return NewProgramChange(nil, 9, 0x80) },
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got.Bytes(), tt.want) {
+				t.Errorf("Bytes() = %v, want %v", got.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTrack_Wait(t *testing.T) {
+	tr := NewTrack().
+		NoteOn(0, Pitch(60), nil, 0).
+		Wait(96).NoteOff(0, Pitch(60), nil, 0).
+		Wait(32).Text("late", TranslateTickTime(16)).
+		At(400).Wait(8).Text("placed", nil)
+	want := NewTrack().
+		NoteOn(0, Pitch(60), nil, 0).
+		NoteOff(0, Pitch(60), TranslateTickTime(96), 0).
+		Text("late", TranslateTickTime(48)).
+		Text("placed", TranslateTickTime(264))
+	if got := tr.Bytes(); !reflect.DeepEqual(got, want.Bytes()) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want.Bytes())
+	}
+	ticks := []int{}
+	deltas := []int{}
+	for _, e := range tr.events {
+		ticks = append(ticks, e.Tick())
+		deltas = append(deltas, e.Delta())
+	}
+	for _, te := range tr.placed {
+		ticks = append(ticks, te.e.Tick())
+	}
+	if want := []int{0, 96, 144, 408}; !reflect.DeepEqual(ticks, want) {
+		t.Errorf("Event.Tick() = %v, want %v", ticks, want)
+	}
+	if want := []int{0, 96, 48}; !reflect.DeepEqual(deltas, want) {
+		t.Errorf("Event.Delta() = %v, want %v", deltas, want)
+	}
+	if err := NewTrack().Wait(-1).Err(); err == nil {
+		t.Errorf("Track.Wait(-1).Err() = nil, want error")
+	}
+}
+
+func TestTrack_After(t *testing.T) {
+	tr := NewTrack().
+		NameAfter("lead", 0).
+		TempoAfter(120, 0).
+		TimeSignatureAfter(3, 2, 24, 8, 0).
+		KeySignatureAfter(2, false, 0).
+		InstrumentAfter(0, 0x10, 8).
+		VolumeAfter(0, 100, 0).
+		SustainAfter(0, true, 4).
+		NoteAfter(0, Pitch(60), 96, 16, 80).
+		NoteOnAfter(0, Pitch(62), 0, 80).
+		NoteOffAfter(0, Pitch(62), 48, 0).
+		PitchBendRampAfter(0, 0, 100, 20, 10, 8).
+		ChannelPressureAfter(0, 30, 2).
+		PitchBendRangeAfter(0, 12, 0, 4).
+		MarkerAfter("end", 200).
+		SysExAfter(GMReset, 1)
+	want := NewTrack().
+		Name("lead", nil).
+		Tempo(120, nil).
+		TimeSignature(3, 2, 24, 8, nil).
+		KeySignature(2, false, nil).
+		Instrument(0, 0x10, TranslateTickTime(8)).
+		Volume(0, 100, nil).
+		Sustain(0, true, TranslateTickTime(4)).
+		Note(0, Pitch(60), 96, TranslateTickTime(16), 80).
+		NoteOn(0, Pitch(62), nil, 80).
+		NoteOff(0, Pitch(62), TranslateTickTime(48), 0).
+		PitchBendRamp(0, 0, 100, 20, 10, TranslateTickTime(8)).
+		ChannelPressure(0, 30, TranslateTickTime(2)).
+		PitchBendRange(0, 12, 0, TranslateTickTime(4)).
+		Marker("end", TranslateTickTime(200)).
+		SysEx(GMReset, TranslateTickTime(1))
+	if err := tr.Err(); err != nil {
+		t.Fatalf("Track.Err() = %v", err)
+	}
+	if got := tr.Bytes(); !reflect.DeepEqual(got, want.Bytes()) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want.Bytes())
+	}
+	tests := []struct {
+		name string
+		t    *Track
+	}{
+		{"negative note delta", NewTrack().NoteAfter(0, Pitch(60), 96, -1, 0)},
+		{"negative controller delta", NewTrack().VolumeAfter(0, 100, -96)},
+		{"negative meta delta", NewTrack().TempoAfter(120, -1)},
+		{"negative sysex delta", NewTrack().SysExAfter(GMReset, -1)},
+		{"delta out of bounds", NewTrack().NoteOnAfter(0, Pitch(60), MaxVLQ+1, 0)},
+		{"negative ramp delta", NewTrack().PitchBendRampAfter(0, 0, 100, 20, 10, -1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.t.Err(); err == nil {
+				t.Errorf("Track.Err() = nil, want error")
+			}
+			if len(tt.t.events) != 0 {
+				t.Errorf("Track.events = %v, want none", tt.t.events)
+			}
+		})
+	}
+}
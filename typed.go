@@ -0,0 +1,170 @@
+package midi
+
+// NoteOnEvent is a note-on event, a velocity of 0 releases the note
+type NoteOnEvent struct{ *NormalEvent }
+
+// Pitch returns the pitch of the note
+func (e NoteOnEvent) Pitch() Pitch { return Pitch(e.param1) }
+
+// Velocity returns the velocity of the note
+func (e NoteOnEvent) Velocity() byte { return e.param2 }
+
+// NoteOffEvent is a note-off event
+type NoteOffEvent struct{ *NormalEvent }
+
+// Pitch returns the pitch of the note
+func (e NoteOffEvent) Pitch() Pitch { return Pitch(e.param1) }
+
+// Velocity returns the velocity the note was released
+func (e NoteOffEvent) Velocity() byte { return e.param2 }
+
+// AfterTouchEvent is a polyphonic aftertouch event
+type AfterTouchEvent struct{ *NormalEvent }
+
+// Pitch returns the pitch of the note
+func (e AfterTouchEvent) Pitch() Pitch { return Pitch(e.param1) }
+
+// Pressure returns the pressure on the note
+func (e AfterTouchEvent) Pressure() byte { return e.param2 }
+
+// ControllerEvent is a controller event
+type ControllerEvent struct{ *NormalEvent }
+
+// Controller returns the changed controller
+func (e ControllerEvent) Controller() Controller { return Controller(e.param1) }
+
+// Value returns the new value of the controller
+func (e ControllerEvent) Value() byte { return e.param2 }
+
+// ProgramChangeEvent is a program change event
+type ProgramChangeEvent struct{ *NormalEvent }
+
+// Program returns the instrument of the event
+func (e ProgramChangeEvent) Program() byte { return e.param1 }
+
+// ChannelPressureEvent is a channel aftertouch event
+type ChannelPressureEvent struct{ *NormalEvent }
+
+// Pressure returns the pressure on the channel
+func (e ChannelPressureEvent) Pressure() byte { return e.param1 }
+
+// PitchBendEvent is a pitch bend event
+type PitchBendEvent struct{ *NormalEvent }
+
+// Value returns the bend, from MinPitchBend to MaxPitchBend
+func (e PitchBendEvent) Value() int16 {
+	return int16(int(e.param2)<<7|int(e.param1)) + MinPitchBend
+}
+
+// TextEvent is a text, copyright, track name, instrument
+// name, lyric, marker or cue point meta event
+type TextEvent struct{ *MetaEvent }
+
+// Text returns the text of the event
+func (e TextEvent) Text() string { return string(e.Payload()) }
+
+// TempoEvent is a set tempo meta event
+type TempoEvent struct{ *MetaEvent }
+
+// Mpqn returns the tempo in microseconds per quarter note
+func (e TempoEvent) Mpqn() Timing {
+	mpqn, _ := tempoOf(e.MetaEvent)
+	return mpqn
+}
+
+// Bpm returns the tempo in beats per minute
+func (e TempoEvent) Bpm() float64 {
+	return MicrosecondsPerMinute / float64(e.Mpqn())
+}
+
+// TimeSignatureEvent is a time signature meta event
+type TimeSignatureEvent struct{ *MetaEvent }
+
+// Numerator returns the numerator of the time signature
+func (e TimeSignatureEvent) Numerator() byte { return e.Payload()[0] }
+
+// Denominator returns the denominator of the time signature
+func (e TimeSignatureEvent) Denominator() int { return 1 << e.Payload()[1] }
+
+// ClocksPerClick returns the midi clocks per metronome click
+func (e TimeSignatureEvent) ClocksPerClick() byte { return e.Payload()[2] }
+
+// ThirtySecondsPerQuarter returns the notated 32nd notes per midi quarter note
+func (e TimeSignatureEvent) ThirtySecondsPerQuarter() byte { return e.Payload()[3] }
+
+// KeySignatureEvent is a key signature meta event
+type KeySignatureEvent struct{ *MetaEvent }
+
+// SharpsFlats returns the number of sharps (positive) or flats (negative)
+func (e KeySignatureEvent) SharpsFlats() int8 { return int8(e.Payload()[0]) }
+
+// Minor returns whether the key is minor
+func (e KeySignatureEvent) Minor() bool { return e.Payload()[1] == 1 }
+
+// Typed returns e as one of the concrete event types of the package, such
+// as NoteOnEvent, ControllerEvent or TempoEvent, so it can be used in type
+// switches. The typed event shares its data with e. Events without a
+// concrete type, or whose data is malformed, are returned as they are
+func Typed(e Event) Event {
+	switch v := e.(type) {
+	case *NormalEvent:
+		switch v._type {
+		case EventNoteOn:
+			return NoteOnEvent{v}
+		case EventNoteOff:
+			return NoteOffEvent{v}
+		case EventAfterTouch:
+			return AfterTouchEvent{v}
+		case EventController:
+			return ControllerEvent{v}
+		case EventProgramChange:
+			return ProgramChangeEvent{v}
+		case EventChannelAfterTouch:
+			return ChannelPressureEvent{v}
+		case EventPitchBend:
+			return PitchBendEvent{v}
+		}
+	case *MetaEvent:
+		n := len(v.Payload())
+		switch {
+		case v._type >= EventText && v._type <= EventCuePoint:
+			return TextEvent{v}
+		case v._type == EventTempo && n == 3:
+			return TempoEvent{v}
+		case v._type == EventTimeSig && n == 4:
+			return TimeSignatureEvent{v}
+		case v._type == EventKeySig && n == 2:
+			return KeySignatureEvent{v}
+		}
+	}
+	return e
+}
+
+// based is implemented by the events of the package and
+// by the typed events embedding them
+type based interface {
+	base() Event
+}
+
+func (e *NormalEvent) base() Event { return e }
+
+func (e *MetaEvent) base() Event { return e }
+
+// untyped returns the event a typed event was made from
+func untyped(e Event) Event {
+	if b, ok := e.(based); ok {
+		return b.base()
+	}
+	return e
+}
+
+// Events returns copies of the events of the track sorted by time, with
+// their absolute times set, as the concrete types returned by Typed
+func (t *Track) Events() []Event {
+	timeline := t.timeline()
+	events := make([]Event, 0, len(timeline))
+	for _, te := range timeline {
+		events = append(events, Typed(te.e))
+	}
+	return events
+}
@@ -0,0 +1,110 @@
+package midi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrack_Events(t *testing.T) {
+	tr := NewTrack().
+		Tempo(120, nil).
+		TimeSignature(6, 3, 36, 8, nil).
+		KeySignature(-3, true, nil).
+		Name("lead", nil).
+		Instrument(1, 0x10, nil).
+		Volume(1, 100, nil).
+		PitchBend(1, -100, nil).
+		ChannelPressure(1, 30, nil).
+		PolyAfterTouch(1, Pitch(61), 40, nil).
+		Note(1, Pitch(60), 96, TranslateTickTime(10), 80).
+		SysEx(GMReset, nil)
+	events := tr.Events()
+	if len(events) != 12 {
+		t.Fatalf("Track.Events() = %d events, want 12", len(events))
+	}
+	for i, e := range events {
+		switch e := e.(type) {
+		case TempoEvent:
+			if e.Mpqn() != 500000 || e.Bpm() != 120 {
+				t.Errorf("TempoEvent = %v, %v", e.Mpqn(), e.Bpm())
+			}
+		case TimeSignatureEvent:
+			if e.Numerator() != 6 || e.Denominator() != 8 || e.ClocksPerClick() != 36 || e.ThirtySecondsPerQuarter() != 8 {
+				t.Errorf("TimeSignatureEvent = %v", e.Payload())
+			}
+		case KeySignatureEvent:
+			if e.SharpsFlats() != -3 || !e.Minor() {
+				t.Errorf("KeySignatureEvent = %v", e.Payload())
+			}
+		case TextEvent:
+			if e.MetaType() != EventTrackName || e.Text() != "lead" {
+				t.Errorf("TextEvent = %v %q", e.MetaType(), e.Text())
+			}
+		case ProgramChangeEvent:
+			if e.Channel() != 1 || e.Program() != 0x10 {
+				t.Errorf("ProgramChangeEvent = %v %v", e.Channel(), e.Program())
+			}
+		case ControllerEvent:
+			if e.Controller() != ControllerVolume || e.Value() != 100 {
+				t.Errorf("ControllerEvent = %v %v", e.Controller(), e.Value())
+			}
+		case PitchBendEvent:
+			if e.Value() != -100 {
+				t.Errorf("PitchBendEvent = %v", e.Value())
+			}
+		case ChannelPressureEvent:
+			if e.Pressure() != 30 || e.Data2() != 0 {
+				t.Errorf("ChannelPressureEvent = %v %v", e.Pressure(), e.Data2())
+			}
+		case AfterTouchEvent:
+			if e.Pitch() != 61 || e.Pressure() != 40 {
+				t.Errorf("AfterTouchEvent = %v %v", e.Pitch(), e.Pressure())
+			}
+		case NoteOnEvent:
+			if e.Pitch() != 60 || e.Velocity() != 80 || e.Tick() != 10 {
+				t.Errorf("NoteOnEvent = %v %v at %v", e.Pitch(), e.Velocity(), e.Tick())
+			}
+		case NoteOffEvent:
+			if e.Type() != EventNoteOff || e.Pitch() != 60 || e.Tick() != 106 || i != 11 {
+				t.Errorf("NoteOffEvent = %v at %v, index %v", e.Pitch(), e.Tick(), i)
+			}
+		case *SysExEvent:
+			if !reflect.DeepEqual(e.Payload(), []byte(GMReset)) || e.Continuation() {
+				t.Errorf("SysExEvent = %v", e.Payload())
+			}
+		default:
+			t.Errorf("Track.Events()[%d] = %T", i, e)
+		}
+	}
+	// typed events can be added back to a track
+	nt := NewTrack()
+	for _, e := range events {
+		if err := nt.AddEvent(e); err != nil {
+			t.Fatalf("Track.AddEvent() error = %v", err)
+		}
+	}
+	if got, want := nt.Bytes(), tr.Bytes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Track.Bytes() = %v, want %v", got, want)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	malformed := &MetaEvent{_type: EventTempo, data: []byte{0x1}}
+	tests := []struct {
+		name string
+		e    Event
+		want Event
+	}{
+		{"nil", nil, nil},
+		{"malformed tempo", malformed, malformed},
+		{"sequence number", &MetaEvent{_type: EventSequence, data: []byte{0, 1}}, &MetaEvent{_type: EventSequence, data: []byte{0, 1}}},
+		{"note-on", &NormalEvent{_type: EventNoteOn}, NoteOnEvent{&NormalEvent{_type: EventNoteOn}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Typed(tt.e); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Typed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}